@@ -0,0 +1,293 @@
+package knn
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// BinaryDocument represents single entry in BinaryKNN index. The
+// bits are packed into words of 64 bits, the first bit is the least
+// significant bit of the first word.
+type BinaryDocument interface {
+	GetID() string
+	GetBits() []uint64
+}
+
+// BinaryResultDocument is wrapper for BinaryDocument but with
+// extra information related to search result
+type BinaryResultDocument struct {
+	Document BinaryDocument
+	Distance int // hamming distance, the lower the better
+}
+
+// BinaryKNN is the index for searching nearest neighbors of
+// binary vectors (e.g perceptual image hashes) by hamming distance.
+// It is based on bit sampling LSH index.
+type BinaryKNN struct {
+	// LSH index which will be used for indexing documents
+	lsh *bitSamplingLsh
+
+	// We store number of bits & words for input validation,
+	// tailMask holds the valid bits of the last word
+	numBits  int
+	numWords int
+	tailMask uint64
+
+	// Just like in KNN, the LSH index only stores document id
+	// so we need another map to get full document info. It
+	// stores binaryEntry of each document.
+	docMap sync.Map
+
+	// The LSH index uses normal map, so mutex is needed to
+	// prevent panic from concurrent read & write.
+	mux sync.RWMutex
+}
+
+// binaryEntry holds single document in BinaryKNN along with its
+// bucket keys, we keep the keys so the document is removed from
+// the right buckets even when its bits have been mutated
+type binaryEntry struct {
+	doc  BinaryDocument
+	keys []uint64
+}
+
+// NewBinaryKNN returns new initialized instance of BinaryKNN index.
+// It returns error when the configs are invalid.
+func NewBinaryKNN(configs BinaryConfigs) (*BinaryKNN, error) {
+	// check input validity
+	if configs.NumBits <= 0 || configs.NumHashTable <= 0 || configs.NumSampledBits <= 0 {
+		return nil, fmt.Errorf("NumBits, NumHashTable & NumSampledBits must be greater than 0")
+	}
+	if configs.NumSampledBits > 64 {
+		return nil, fmt.Errorf("NumSampledBits must not be greater than 64")
+	}
+	tailMask := uint64(math.MaxUint64)
+	if configs.NumBits%64 != 0 {
+		tailMask = 1<<uint(configs.NumBits%64) - 1
+	}
+	return &BinaryKNN{
+		lsh: newBitSamplingLsh(
+			configs.NumBits,
+			configs.NumHashTable,
+			configs.NumSampledBits,
+		),
+		numBits:  configs.NumBits,
+		numWords: (configs.NumBits + 63) / 64,
+		tailMask: tailMask,
+		docMap:   sync.Map{},
+	}, nil
+}
+
+// Add is used for introduce new document to index. If document
+// with the same id already exists, it will be replaced.
+func (n *BinaryKNN) Add(doc BinaryDocument) error {
+	// check input validity
+	if doc == nil || len(doc.GetID()) == 0 || len(doc.GetBits()) == 0 {
+		return fmt.Errorf("%w: trying to insert bad document", ErrInvalidDocument)
+	}
+	if len(doc.GetBits()) != n.numWords {
+		return &DimensionError{Expected: n.numWords, Got: len(doc.GetBits())}
+	}
+	// acquire lock
+	n.mux.Lock()
+	// defer unlock
+	defer n.mux.Unlock()
+
+	// remove old document from LSH index
+	if v, ok := n.docMap.Load(doc.GetID()); ok {
+		n.lsh.delete(v.(*binaryEntry).keys, doc.GetID())
+	}
+	// insert document to LSH index
+	keys := n.lsh.insert(doc.GetBits(), doc.GetID())
+	// insert document to map
+	n.docMap.Store(doc.GetID(), &binaryEntry{doc: doc, keys: keys})
+
+	return nil
+}
+
+// Query returns maximum `k` similar documents. The result
+// already sorted from most similar to least similar documents.
+func (n *BinaryKNN) Query(vector []uint64, k int) ([]BinaryResultDocument, error) {
+	// check input validity
+	if len(vector) != n.numWords {
+		return nil, &DimensionError{Expected: n.numWords, Got: len(vector)}
+	}
+	if k <= 0 {
+		return nil, ErrInvalidK
+	}
+	// acquire read lock
+	n.mux.RLock()
+	// defer read unlock
+	defer n.mux.RUnlock()
+
+	// get ids of similar documents
+	ids := n.lsh.query(vector)
+	// get full document info from docMap including
+	// distance from input vector
+	resultDocs := make([]BinaryResultDocument, 0, len(ids))
+	for _, id := range ids {
+		v, ok := n.docMap.Load(id)
+		if !ok {
+			continue
+		}
+		doc := v.(*binaryEntry).doc
+		resultDocs = append(resultDocs, BinaryResultDocument{
+			Document: doc,
+			Distance: calcHammingDistance(doc.GetBits(), vector, n.tailMask),
+		})
+	}
+	// sort by distance from minimum to maximum
+	sort.Slice(resultDocs, func(i int, j int) bool {
+		return resultDocs[i].Distance < resultDocs[j].Distance
+	})
+	// cut the result into max k documents
+	if len(resultDocs) > k {
+		resultDocs = resultDocs[:k]
+	}
+	return resultDocs, nil
+}
+
+// calcHammingDistance returns number of different bits between
+// `v1` & `v2`, only the bits in `tailMask` are compared on the last
+// word. Input `v1` & `v2` assummed has same length.
+func calcHammingDistance(v1, v2 []uint64, tailMask uint64) int {
+	distance := 0
+	last := len(v1) - 1
+	for i := 0; i < last; i++ {
+		distance += bits.OnesCount64(v1[i] ^ v2[i])
+	}
+	distance += bits.OnesCount64((v1[last] ^ v2[last]) & tailMask)
+	return distance
+}
+
+// Delete is used to delete appointed document from index.
+// The document will literally deleted from memory. It returns
+// ErrNotFound when the document is not in the index.
+func (n *BinaryKNN) Delete(docID string) error {
+	// check input validity
+	if len(docID) == 0 {
+		return fmt.Errorf("%w: document id must not empty", ErrInvalidDocument)
+	}
+	// acquire lock
+	n.mux.Lock()
+	// defer unlock
+	defer n.mux.Unlock()
+
+	v, ok := n.docMap.Load(docID)
+	if !ok {
+		return ErrNotFound
+	}
+	// delete from lsh index
+	n.lsh.delete(v.(*binaryEntry).keys, docID)
+	// delete document from map
+	n.docMap.Delete(docID)
+
+	return nil
+}
+
+// Get is used to get single document from index.
+// If document not found returns ErrNotFound.
+func (n *BinaryKNN) Get(docID string) (BinaryDocument, error) {
+	// check input validity
+	if len(docID) == 0 {
+		return nil, fmt.Errorf("%w: document id must not empty", ErrInvalidDocument)
+	}
+	// load document from doc map
+	v, ok := n.docMap.Load(docID)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return v.(*binaryEntry).doc, nil
+}
+
+// bitSamplingLsh implements LSH for hamming distance. Each hash
+// table samples fixed random bit positions of the input vector
+// & use the sampled bits as the bucket key.
+type bitSamplingLsh struct {
+	// sampled bit positions for each hash table
+	positions [][]int
+	// hash tables
+	tables []map[uint64][]string
+}
+
+// newBitSamplingLsh creates bit sampling LSH. numBits is the
+// number of bits in input vector, l is the number of hash tables,
+// m is the number of sampled bits for each table (max 64).
+func newBitSamplingLsh(numBits, l, m int) *bitSamplingLsh {
+	random := rand.New(rand.NewSource(1))
+	positions := make([][]int, l)
+	tables := make([]map[uint64][]string, l)
+	for i := range positions {
+		positions[i] = make([]int, m)
+		for j := range positions[i] {
+			positions[i][j] = random.Intn(numBits)
+		}
+		tables[i] = make(map[uint64][]string)
+	}
+	return &bitSamplingLsh{
+		positions: positions,
+		tables:    tables,
+	}
+}
+
+// hash returns the bucket key of `vector` for table `i`
+func (index *bitSamplingLsh) hash(vector []uint64, i int) uint64 {
+	key := uint64(0)
+	for j, pos := range index.positions[i] {
+		bit := (vector[pos/64] >> uint(pos%64)) & 1
+		key |= bit << uint(j)
+	}
+	return key
+}
+
+// insert adds `id` to the buckets of `vector`, it returns the
+// bucket keys for each table
+func (index *bitSamplingLsh) insert(vector []uint64, id string) []uint64 {
+	keys := make([]uint64, len(index.tables))
+	for i, table := range index.tables {
+		keys[i] = index.hash(vector, i)
+		table[keys[i]] = append(table[keys[i]], id)
+	}
+	return keys
+}
+
+// query returns the ids of candidates in un-sorted order
+func (index *bitSamplingLsh) query(vector []uint64) []string {
+	seen := make(map[string]bool)
+	ids := make([]string, 0)
+	for i, table := range index.tables {
+		for _, id := range table[index.hash(vector, i)] {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// delete removes `id` from the buckets with `keys`
+func (index *bitSamplingLsh) delete(keys []uint64, id string) {
+	for i, table := range index.tables {
+		key := keys[i]
+		bucket := table[key]
+		for j := range bucket {
+			if bucket[j] != id {
+				continue
+			}
+			bucket[j] = bucket[len(bucket)-1]
+			bucket = bucket[:len(bucket)-1]
+			break
+		}
+		if len(bucket) == 0 {
+			delete(table, key)
+		} else {
+			table[key] = bucket
+		}
+	}
+}
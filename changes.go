@@ -0,0 +1,267 @@
+package knn
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ChangeOp represents type of mutation in the index
+type ChangeOp int
+
+const (
+	// OpAdd is new document added to the index
+	OpAdd ChangeOp = iota
+	// OpUpsert is existing document replaced by the new one
+	OpUpsert
+	// OpDelete is document deleted by Delete or DeleteWhere
+	OpDelete
+	// OpEvict is document evicted because the index is full
+	OpEvict
+	// OpExpire is expired document swept from the index
+	OpExpire
+	// OpReset is all documents deleted by Reset, the event
+	// doesn't have document
+	OpReset
+)
+
+// parseChangeOp returns ChangeOp which String() is `s`
+func parseChangeOp(s string) (ChangeOp, bool) {
+	for op := OpAdd; op <= OpReset; op++ {
+		if op.String() == s {
+			return op, true
+		}
+	}
+	return 0, false
+}
+
+func (op ChangeOp) String() string {
+	switch op {
+	case OpAdd:
+		return "add"
+	case OpUpsert:
+		return "upsert"
+	case OpDelete:
+		return "delete"
+	case OpEvict:
+		return "evict"
+	case OpExpire:
+		return "expire"
+	case OpReset:
+		return "reset"
+	}
+	return "unknown"
+}
+
+// ChangeEvent represents single mutation in the index
+type ChangeEvent struct {
+	// Seq is the sequence number of the mutation, it is increased
+	// monotonically starting from 1
+	Seq uint64
+	Op  ChangeOp
+	ID  string
+
+	// Document is the added or removed document, SparseDocument is
+	// set instead for sparse document
+	Document       Document
+	SparseDocument SparseDocument
+
+	// ExpiresAt is the expiry time of the added document, zero
+	// time means it never expires
+	ExpiresAt time.Time
+}
+
+// Hook receives mutations of the index. The methods are called
+// after the mutation is applied, in order of the sequence number.
+// They are called outside of the index lock, so they could read
+// from the index, register hooks, subscribe or close subscriptions,
+// but they must not write to it since the writes wait for the hooks
+// to return.
+type Hook interface {
+	// OnAdd is called for OpAdd & OpUpsert
+	OnAdd(event ChangeEvent)
+	// OnDelete is called for OpDelete, OpEvict, OpExpire & OpReset
+	OnDelete(event ChangeEvent)
+}
+
+// BackpressurePolicy represents what to do when the buffer of
+// subscription is full
+type BackpressurePolicy int
+
+const (
+	// BackpressureBlock makes the writes wait until the subscriber
+	// receives the event
+	BackpressureBlock BackpressurePolicy = iota
+	// BackpressureDrop drops the event, the number of dropped events
+	// is reported by Subscription.Dropped()
+	BackpressureDrop
+)
+
+// Subscription is channel feed of the index mutations
+type Subscription struct {
+	// C receives the events in order of their sequence number,
+	// it is closed when the subscription is closed
+	C <-chan ChangeEvent
+
+	ch        chan ChangeEvent
+	policy    BackpressurePolicy
+	dropped   uint64
+	done      chan struct{}
+	closeOnce sync.Once
+	index     *KNN
+
+	// mux guards ch from being closed while sending, closed is
+	// set once ch is closed
+	mux    sync.Mutex
+	closed bool
+}
+
+// Dropped returns number of events dropped because the buffer
+// is full, it is only used by BackpressureDrop
+func (s *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Close stops the subscription & closes its channel
+func (s *Subscription) Close() {
+	s.closeOnce.Do(func() {
+		// unblock the pending send first, so we
+		// could acquire the lock of subscription
+		close(s.done)
+		n := s.index
+		n.mux.Lock()
+		for i, sub := range n.subs {
+			if sub == s {
+				n.subs = append(n.subs[:i], n.subs[i+1:]...)
+				break
+			}
+		}
+		atomic.AddInt32(&n.numListeners, -1)
+		n.mux.Unlock()
+
+		s.mux.Lock()
+		defer s.mux.Unlock()
+		s.closed = true
+		close(s.ch)
+	})
+}
+
+// send delivers `event` according to the backpressure policy, it
+// does nothing when the subscription is closed
+func (s *Subscription) send(event ChangeEvent) {
+	// acquire lock
+	s.mux.Lock()
+	// defer unlock
+	defer s.mux.Unlock()
+
+	if s.closed {
+		return
+	}
+	if s.policy == BackpressureDrop {
+		select {
+		case s.ch <- event:
+		default:
+			atomic.AddUint64(&s.dropped, 1)
+		}
+		return
+	}
+	select {
+	case s.ch <- event:
+	case <-s.done:
+	}
+}
+
+// RegisterHook registers `hook` for receiving the mutations
+// applied after it is registered
+func (n *KNN) RegisterHook(hook Hook) {
+	// acquire lock
+	n.mux.Lock()
+	// defer unlock
+	defer n.mux.Unlock()
+
+	n.hooks = append(n.hooks, hook)
+	atomic.AddInt32(&n.numListeners, 1)
+}
+
+// Subscribe returns channel feed of the mutations applied after it
+// is subscribed. `bufferSize` is the size of the channel buffer &
+// `policy` decides what to do when the buffer is full. Call Close()
+// on the subscription once it is no longer used.
+func (n *KNN) Subscribe(bufferSize int, policy BackpressurePolicy) *Subscription {
+	ch := make(chan ChangeEvent, bufferSize)
+	s := &Subscription{
+		C:      ch,
+		ch:     ch,
+		policy: policy,
+		done:   make(chan struct{}),
+		index:  n,
+	}
+	// acquire lock
+	n.mux.Lock()
+	// defer unlock
+	defer n.mux.Unlock()
+
+	n.subs = append(n.subs, s)
+	atomic.AddInt32(&n.numListeners, 1)
+	return s
+}
+
+// record adds event of mutation on document with `id` in entry `e`
+// to the pending events. The sequence number is always increased,
+// but the event is only kept when somebody listens. The caller must
+// hold the lock.
+func (n *KNN) record(op ChangeOp, id string, e entry) {
+	n.seq++
+	if atomic.LoadInt32(&n.numListeners) == 0 {
+		return
+	}
+	event := ChangeEvent{Seq: n.seq, Op: op, ID: id}
+	if e.expiresAt > 0 {
+		event.ExpiresAt = time.Unix(0, e.expiresAt)
+	}
+	switch doc := e.doc.(type) {
+	case sparseDoc:
+		event.SparseDocument = doc.SparseDocument
+	case Document:
+		event.Document = doc
+	}
+	n.pending = append(n.pending, event)
+}
+
+// unlock releases the write lock then dispatches the pending events.
+// The hooks & the subscriptions are copied under the lock, then the
+// events are dispatched without holding any lock once the events of
+// the previous write are dispatched. So the events are dispatched in
+// order of their sequence number, and the hooks could still register
+// hooks, subscribe or close subscriptions.
+func (n *KNN) unlock() {
+	events := n.pending
+	n.pending = nil
+	if len(events) == 0 {
+		n.mux.Unlock()
+		return
+	}
+	hooks := append([]Hook(nil), n.hooks...)
+	subs := append([]*Subscription(nil), n.subs...)
+	prev, done := n.dispatched, make(chan struct{})
+	n.dispatched = done
+	n.mux.Unlock()
+	defer close(done)
+
+	// wait for the events of the previous write
+	if prev != nil {
+		<-prev
+	}
+	for _, event := range events {
+		for _, hook := range hooks {
+			if event.Op == OpAdd || event.Op == OpUpsert {
+				hook.OnAdd(event)
+			} else {
+				hook.OnDelete(event)
+			}
+		}
+		for _, sub := range subs {
+			sub.send(event)
+		}
+	}
+}
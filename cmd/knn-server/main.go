@@ -0,0 +1,93 @@
+// Command knn-server exposes KNN index through REST endpoints with
+// JSON body, so the index could be used from any language.
+//
+// The index configs are read from JSON file passed to -config, the
+// flags which are set explicitly override the values in the file:
+//
+//	knn-server -config configs.json -addr :8080 -snapshot index.jsonl
+//
+// When -snapshot is set, the documents are loaded from the file on
+// start (if it exists) & saved to it on shutdown.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/riandyrn/go-knn"
+	"github.com/riandyrn/go-knn/internal/configfile"
+	"github.com/riandyrn/go-knn/internal/indexfile"
+)
+
+func main() {
+	configs := configfile.Default()
+	var (
+		addr            = flag.String("addr", ":8080", "address to listen on")
+		configPath      = flag.String("config", "", "path of JSON file holding the index configs")
+		snapshotPath    = flag.String("snapshot", "", "path of snapshot file loaded on start & saved on shutdown")
+		shutdownTimeout = flag.Duration("shutdown-timeout", 10*time.Second, "maximum duration for finishing in-flight requests on shutdown")
+		maxBodyBytes    = flag.Int64("max-body-bytes", 32<<20, "maximum size of request body in bytes")
+	)
+	configs.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	// read configs from file, the flags which are set
+	// explicitly override the values in the file
+	if len(*configPath) > 0 {
+		if err := configs.Load(flag.CommandLine, *configPath); err != nil {
+			log.Fatalf("unable to read configs due: %v", err)
+		}
+	}
+	if configs.VectorDimension <= 0 {
+		log.Fatalf("vector dimension must be set through -dim or -config")
+	}
+
+	// prepare index
+	if err := configs.KNNConfigs().Validate(); err != nil {
+		log.Fatalf("invalid configs due: %v", err)
+	}
+	index := knn.NewKNN(configs.KNNConfigs())
+	defer index.Close()
+	if len(*snapshotPath) > 0 {
+		err := indexfile.Load(index, *snapshotPath)
+		if err != nil && !os.IsNotExist(err) {
+			log.Fatalf("unable to load snapshot due: %v", err)
+		}
+		log.Printf("loaded %v documents from %v", index.Len(), *snapshotPath)
+	}
+
+	// serve until receiving termination signal
+	srv := &http.Server{Addr: *addr, Handler: newServer(index, *maxBodyBytes)}
+	errCh := make(chan error, 1)
+	go func() {
+		log.Printf("listening on %v", *addr)
+		errCh <- srv.ListenAndServe()
+	}()
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case err := <-errCh:
+		log.Fatalf("unable to serve due: %v", err)
+	case sig := <-sigCh:
+		log.Printf("received %v, shutting down", sig)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("unable to shutdown gracefully due: %v", err)
+	}
+
+	// save snapshot after there is no more in-flight request
+	if len(*snapshotPath) > 0 {
+		if err := indexfile.Save(index, *snapshotPath); err != nil {
+			log.Fatalf("unable to save snapshot due: %v", err)
+		}
+		log.Printf("saved %v documents to %v", index.Len(), *snapshotPath)
+	}
+}
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/riandyrn/go-knn"
+)
+
+// server exposes KNN index through REST endpoints:
+//
+//	POST   /documents           add document, fails when the id exists
+//	POST   /batch/documents     add documents, replacing the existing ones
+//	PUT    /documents/{id}      add or replace document
+//	GET    /documents/{id}      get document
+//	DELETE /documents/{id}      delete document
+//	POST   /query               query by vector
+//	POST   /query/id            query by id of document in the index
+//	POST   /query/radius        query documents within radius of vector
+//
+// The documents are carried as JSON with id, vector & metadata fields.
+// The request body larger than maxBodyBytes is rejected.
+type server struct {
+	index        *knn.KNN
+	maxBodyBytes int64
+
+	// idLocks serializes the writes of documents with the same id,
+	// so no other write could add the document between the check
+	// & the add of POST /documents. The id is mapped into one of
+	// the locks by its hash.
+	idLocks [numIDLocks]sync.Mutex
+}
+
+const numIDLocks = 64
+
+func newServer(index *knn.KNN, maxBodyBytes int64) http.Handler {
+	s := &server{index: index, maxBodyBytes: maxBodyBytes}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/documents", s.handleAdd)
+	mux.HandleFunc("/documents/", s.handleDocument)
+	mux.HandleFunc("/batch/documents", s.handleBatchAdd)
+	mux.HandleFunc("/query", s.handleQuery)
+	mux.HandleFunc("/query/id", s.handleQueryByID)
+	mux.HandleFunc("/query/radius", s.handleQueryRadius)
+	return mux
+}
+
+type batchRequest struct {
+	Documents []*knn.BasicDocument `json:"documents"`
+}
+
+type queryRequest struct {
+	Vector []float64 `json:"vector"`
+	K      int       `json:"k"`
+}
+
+type queryByIDRequest struct {
+	ID string `json:"id"`
+	K  int    `json:"k"`
+}
+
+type radiusRequest struct {
+	Vector []float64 `json:"vector"`
+	Radius float64   `json:"radius"`
+}
+
+type resultDocument struct {
+	ID       string                 `json:"id"`
+	Vector   []float64              `json:"vector"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	Distance float64                `json:"distance"`
+}
+
+type queryResponse struct {
+	Results []resultDocument `json:"results"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (s *server) handleAdd(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	var doc knn.BasicDocument
+	if !s.decodeBody(w, r, &doc) {
+		return
+	}
+	unlock := s.lockIDs(doc.ID)
+	defer unlock()
+	if _, err := s.index.Get(doc.ID); err == nil {
+		writeError(w, http.StatusConflict, fmt.Errorf("document %v already exists", doc.ID))
+		return
+	}
+	if err := s.index.Add(&doc); err != nil {
+		writeIndexError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, &doc)
+}
+
+func (s *server) handleBatchAdd(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	var req batchRequest
+	if !s.decodeBody(w, r, &req) {
+		return
+	}
+	docs := make([]knn.Document, 0, len(req.Documents))
+	ids := make([]string, 0, len(req.Documents))
+	for _, doc := range req.Documents {
+		if doc == nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("document must not null"))
+			return
+		}
+		docs = append(docs, doc)
+		ids = append(ids, doc.ID)
+	}
+	unlock := s.lockIDs(ids...)
+	defer unlock()
+	if err := s.index.AddBatch(docs); err != nil {
+		writeIndexError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int{"added": len(docs)})
+}
+
+func (s *server) handleDocument(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/documents/")
+	if len(id) == 0 || strings.Contains(id, "/") {
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown path: %v", r.URL.Path))
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		doc, err := s.index.Get(id)
+		if err != nil {
+			writeIndexError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, toBasicDocument(doc))
+	case http.MethodPut:
+		var doc knn.BasicDocument
+		if !s.decodeBody(w, r, &doc) {
+			return
+		}
+		if len(doc.ID) > 0 && doc.ID != id {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("document id %v doesn't match path id %v", doc.ID, id))
+			return
+		}
+		doc.ID = id
+		unlock := s.lockIDs(id)
+		defer unlock()
+		if err := s.index.Add(&doc); err != nil {
+			writeIndexError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, &doc)
+	case http.MethodDelete:
+		if err := s.index.Delete(id); err != nil {
+			writeIndexError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, PUT, DELETE")
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %v is not allowed", r.Method))
+	}
+}
+
+func (s *server) handleQuery(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	var req queryRequest
+	if !s.decodeBody(w, r, &req) {
+		return
+	}
+	resultDocs, err := s.index.Query(req.Vector, req.K)
+	if err != nil {
+		writeIndexError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toQueryResponse(resultDocs))
+}
+
+func (s *server) handleQueryByID(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	var req queryByIDRequest
+	if !s.decodeBody(w, r, &req) {
+		return
+	}
+	resultDocs, err := s.index.QueryByID(req.ID, req.K)
+	if err != nil {
+		writeIndexError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toQueryResponse(resultDocs))
+}
+
+func (s *server) handleQueryRadius(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	var req radiusRequest
+	if !s.decodeBody(w, r, &req) {
+		return
+	}
+	if req.Radius < 0 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("value of radius must not negative"))
+		return
+	}
+	resultDocs, err := s.index.QueryRadius(req.Vector, req.Radius)
+	if err != nil {
+		writeIndexError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toQueryResponse(resultDocs))
+}
+
+// lockIDs acquires the locks of `ids`, it returns function for
+// releasing them. The locks are always acquired in the same order
+// to avoid deadlock between the requests with multiple ids.
+func (s *server) lockIDs(ids ...string) func() {
+	var locked [numIDLocks]bool
+	for _, id := range ids {
+		h := fnv.New32a()
+		h.Write([]byte(id))
+		locked[h.Sum32()%numIDLocks] = true
+	}
+	indexes := make([]int, 0, len(ids))
+	for i := range locked {
+		if locked[i] {
+			indexes = append(indexes, i)
+		}
+	}
+	for _, i := range indexes {
+		s.idLocks[i].Lock()
+	}
+	return func() {
+		for _, i := range indexes {
+			s.idLocks[i].Unlock()
+		}
+	}
+}
+
+// toBasicDocument converts `doc` into its JSON form, the metadata
+// is only set when the document implements MetadataDocument
+func toBasicDocument(doc knn.Document) *knn.BasicDocument {
+	basicDoc := &knn.BasicDocument{ID: doc.GetID(), Vector: doc.GetVector()}
+	if m, ok := doc.(knn.MetadataDocument); ok {
+		basicDoc.Metadata = m.GetMetadata()
+	}
+	return basicDoc
+}
+
+func toQueryResponse(resultDocs []knn.ResultDocument) queryResponse {
+	resp := queryResponse{Results: make([]resultDocument, 0, len(resultDocs))}
+	for _, resultDoc := range resultDocs {
+		// the server only adds dense documents
+		if resultDoc.Document == nil {
+			continue
+		}
+		doc := toBasicDocument(resultDoc.Document)
+		resp.Results = append(resp.Results, resultDocument{
+			ID:       doc.ID,
+			Vector:   doc.Vector,
+			Metadata: doc.Metadata,
+			Distance: resultDoc.Distance,
+		})
+	}
+	return resp
+}
+
+// allowMethod writes error response when the request method is
+// not `method`, it returns true when the method is allowed
+func allowMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method == method {
+		return true
+	}
+	w.Header().Set("Allow", method)
+	writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %v is not allowed", r.Method))
+	return false
+}
+
+// decodeBody decodes JSON body of `r` into `v`, it writes error
+// response & returns false when the body is malformed or larger
+// than maxBodyBytes.
+func (s *server) decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, s.maxBodyBytes))
+	if err != nil && int64(len(body)) >= s.maxBodyBytes {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("request body is larger than %v bytes", s.maxBodyBytes))
+		return false
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unable to read request body due: %w", err))
+		return false
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unable to decode request body due: %w", err))
+		return false
+	}
+	return true
+}
+
+// writeIndexError writes error returned by the index with the
+// status code matching the error
+func writeIndexError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, knn.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, knn.ErrMemoryLimit):
+		status = http.StatusInsufficientStorage
+	case errors.Is(err, knn.ErrInvalidDocument),
+		errors.Is(err, knn.ErrDimensionMismatch),
+		errors.Is(err, knn.ErrEmptyVector),
+		errors.Is(err, knn.ErrInvalidVector),
+		errors.Is(err, knn.ErrInvalidK):
+		status = http.StatusBadRequest
+	}
+	writeError(w, status, err)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		status = http.StatusInternalServerError
+		body = []byte(`{"error":"unable to encode response"}`)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)+1))
+	w.WriteHeader(status)
+	w.Write(append(body, '\n'))
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/riandyrn/go-knn"
+)
+
+func TestServer(t *testing.T) {
+	// prepare server, use single hyperplane with large slot
+	// so all documents land on the same bucket
+	index := knn.NewKNN(knn.Configs{
+		VectorDimension: 2,
+		NumHashTable:    1,
+		NumHyperplane:   1,
+		SlotSize:        1000000,
+	})
+	srv := httptest.NewServer(newServer(index, 1024))
+	defer srv.Close()
+
+	testCases := []struct {
+		Name      string
+		Method    string
+		Path      string
+		Body      string
+		ExpStatus int
+		ExpIDs    []string
+	}{
+		{
+			Name:      "Test Add",
+			Method:    http.MethodPost,
+			Path:      "/documents",
+			Body:      `{"id":"doc_1","vector":[0,0],"metadata":{"label":"cat"}}`,
+			ExpStatus: http.StatusCreated,
+		},
+		{
+			Name:      "Test Add Existing",
+			Method:    http.MethodPost,
+			Path:      "/documents",
+			Body:      `{"id":"doc_1","vector":[0,0]}`,
+			ExpStatus: http.StatusConflict,
+		},
+		{
+			Name:      "Test Add Dimension Mismatch",
+			Method:    http.MethodPost,
+			Path:      "/documents",
+			Body:      `{"id":"doc_2","vector":[0]}`,
+			ExpStatus: http.StatusBadRequest,
+		},
+		{
+			Name:      "Test Add Malformed Body",
+			Method:    http.MethodPost,
+			Path:      "/documents",
+			Body:      `{"id":`,
+			ExpStatus: http.StatusBadRequest,
+		},
+		{
+			Name:      "Test Add Body Too Large",
+			Method:    http.MethodPost,
+			Path:      "/documents",
+			Body:      `{"id":"doc_2","vector":[0,0],"metadata":{"label":"` + strings.Repeat("x", 1024) + `"}}`,
+			ExpStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			Name:      "Test Batch Add",
+			Method:    http.MethodPost,
+			Path:      "/batch/documents",
+			Body:      `{"documents":[{"id":"doc_2","vector":[0,1]},{"id":"doc_3","vector":[0,3]}]}`,
+			ExpStatus: http.StatusOK,
+		},
+		{
+			Name:      "Test Upsert",
+			Method:    http.MethodPut,
+			Path:      "/documents/doc_4",
+			Body:      `{"vector":[0,7]}`,
+			ExpStatus: http.StatusOK,
+		},
+		{
+			Name:      "Test Upsert Mismatch ID",
+			Method:    http.MethodPut,
+			Path:      "/documents/doc_4",
+			Body:      `{"id":"doc_5","vector":[0,7]}`,
+			ExpStatus: http.StatusBadRequest,
+		},
+		{
+			Name:      "Test Get",
+			Method:    http.MethodGet,
+			Path:      "/documents/doc_1",
+			ExpStatus: http.StatusOK,
+		},
+		{
+			Name:      "Test Get Missing",
+			Method:    http.MethodGet,
+			Path:      "/documents/doc_5",
+			ExpStatus: http.StatusNotFound,
+		},
+		{
+			Name:      "Test Query",
+			Method:    http.MethodPost,
+			Path:      "/query",
+			Body:      `{"vector":[0,0],"k":2}`,
+			ExpStatus: http.StatusOK,
+			ExpIDs:    []string{"doc_1", "doc_2"},
+		},
+		{
+			Name:      "Test Query Invalid K",
+			Method:    http.MethodPost,
+			Path:      "/query",
+			Body:      `{"vector":[0,0],"k":0}`,
+			ExpStatus: http.StatusBadRequest,
+		},
+		{
+			Name:      "Test Query By ID",
+			Method:    http.MethodPost,
+			Path:      "/query/id",
+			Body:      `{"id":"doc_4","k":2}`,
+			ExpStatus: http.StatusOK,
+			ExpIDs:    []string{"doc_3", "doc_2"},
+		},
+		{
+			Name:      "Test Query Radius",
+			Method:    http.MethodPost,
+			Path:      "/query/radius",
+			Body:      `{"vector":[0,0],"radius":1}`,
+			ExpStatus: http.StatusOK,
+			ExpIDs:    []string{"doc_1", "doc_2"},
+		},
+		{
+			Name:      "Test Delete",
+			Method:    http.MethodDelete,
+			Path:      "/documents/doc_1",
+			ExpStatus: http.StatusNoContent,
+		},
+		{
+			Name:      "Test Delete Missing",
+			Method:    http.MethodDelete,
+			Path:      "/documents/doc_1",
+			ExpStatus: http.StatusNotFound,
+		},
+		{
+			Name:      "Test Add Document With Batch ID",
+			Method:    http.MethodPost,
+			Path:      "/documents",
+			Body:      `{"id":"batch","vector":[0,100]}`,
+			ExpStatus: http.StatusCreated,
+		},
+		{
+			Name:      "Test Get Document With Batch ID",
+			Method:    http.MethodGet,
+			Path:      "/documents/batch",
+			ExpStatus: http.StatusOK,
+		},
+		{
+			Name:      "Test Method Not Allowed",
+			Method:    http.MethodGet,
+			Path:      "/query",
+			ExpStatus: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			req, err := http.NewRequest(testCase.Method, srv.URL+testCase.Path, bytes.NewBufferString(testCase.Body))
+			if err != nil {
+				t.Fatalf("unable to create request due: %v", err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("unable to send request due: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != testCase.ExpStatus {
+				t.Fatalf("unexpected status, expected: %v, got: %v", testCase.ExpStatus, resp.StatusCode)
+			}
+			if testCase.ExpIDs == nil {
+				return
+			}
+			var queryResp queryResponse
+			if err := json.NewDecoder(resp.Body).Decode(&queryResp); err != nil {
+				t.Fatalf("unable to decode response due: %v", err)
+			}
+			if len(queryResp.Results) != len(testCase.ExpIDs) {
+				t.Fatalf("unexpected number of results, expected: %v, got: %v", len(testCase.ExpIDs), len(queryResp.Results))
+			}
+			for i, result := range queryResp.Results {
+				if result.ID != testCase.ExpIDs[i] {
+					t.Fatalf("unexpected result at %v, expected: %v, got: %v", i, testCase.ExpIDs[i], result.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestServerConcurrentAdd(t *testing.T) {
+	index := knn.NewKNN(knn.Configs{
+		VectorDimension: 2,
+		NumHashTable:    1,
+		NumHyperplane:   1,
+		SlotSize:        1000000,
+	})
+	srv := httptest.NewServer(newServer(index, 1024))
+	defer srv.Close()
+
+	// only one of the concurrent adds of the same id must succeed
+	numRequests := 20
+	statuses := make(chan int, numRequests)
+	var wg sync.WaitGroup
+	for i := 0; i < numRequests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := http.Post(srv.URL+"/documents", "application/json", bytes.NewBufferString(`{"id":"doc_1","vector":[0,0]}`))
+			if err != nil {
+				t.Errorf("unable to send request due: %v", err)
+				return
+			}
+			resp.Body.Close()
+			statuses <- resp.StatusCode
+		}()
+	}
+	wg.Wait()
+	close(statuses)
+	numCreated := 0
+	for status := range statuses {
+		switch status {
+		case http.StatusCreated:
+			numCreated++
+		case http.StatusConflict:
+		default:
+			t.Fatalf("unexpected status: %v", status)
+		}
+	}
+	if numCreated != 1 {
+		t.Fatalf("unexpected number of created documents, expected: 1, got: %v", numCreated)
+	}
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/riandyrn/go-knn"
+	"github.com/riandyrn/go-knn/internal/configfile"
+	"github.com/riandyrn/go-knn/internal/indexfile"
+	knnio "github.com/riandyrn/go-knn/io"
+)
+
+// runBuild builds index from documents file then saves it
+func runBuild(args []string, stdout io.Writer) error {
+	fs := newFlagSet("build", stdout)
+	var f indexFlags
+	f.register(fs)
+	inputPath := fs.String("input", "", "path of documents file, each line is JSON object with id, vector & metadata fields, or CSV with id & vector (space separated values) columns, or vectors file in fvecs/bvecs format")
+	format := fs.String("format", "", "format of documents file: jsonl, csv, fvecs or bvecs, default is based on the file extension")
+	batchSize := fs.Int("batch-size", 1000, "number of vectors added in single batch for fvecs/bvecs file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(*inputPath) == 0 {
+		return fmt.Errorf("path of documents file must be set through -input")
+	}
+	if len(f.path) == 0 {
+		return fmt.Errorf("path of index file must be set through -index")
+	}
+	if err := f.loadConfigs(fs); err != nil {
+		return fmt.Errorf("unable to read configs due: %w", err)
+	}
+	if len(*format) == 0 {
+		*format = "jsonl"
+		if vf, err := knnio.VecsFormatOf(*inputPath); err == nil {
+			*format = vf.String()
+		} else if filepath.Ext(*inputPath) == ".csv" {
+			*format = "csv"
+		}
+	}
+	// add documents to index
+	start := time.Now()
+	var index *knn.KNN
+	switch *format {
+	case "jsonl", "csv":
+		if f.configs.VectorDimension <= 0 {
+			return fmt.Errorf("vector dimension must be set through -dim or configs file")
+		}
+		var err error
+		if index, err = f.newIndex(); err != nil {
+			return err
+		}
+		if err := buildFromDocuments(index, *inputPath, *format, stdout); err != nil {
+			return err
+		}
+	case knnio.Fvecs.String(), knnio.Bvecs.String():
+		vf := knnio.Fvecs
+		if *format == knnio.Bvecs.String() {
+			vf = knnio.Bvecs
+		}
+		var err error
+		index, err = buildFromVecs(&f, *inputPath, vf, *batchSize)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown format of documents file: %v", *format)
+	}
+	elapsed := time.Since(start)
+	// save index & its configs
+	if err := indexfile.Save(index, f.path); err != nil {
+		return fmt.Errorf("unable to save index due: %w", err)
+	}
+	if err := configfile.Write(indexfile.ConfigsPath(f.path), f.configs); err != nil {
+		return fmt.Errorf("unable to save configs due: %w", err)
+	}
+	fmt.Fprintf(stdout, "built index of %v documents in %v\n", index.Len(), elapsed.Round(time.Millisecond))
+	fmt.Fprintf(stdout, "saved to %v\n", f.path)
+	return nil
+}
+
+// buildFromDocuments adds documents from JSONL or CSV file at `path`
+// to `index`, the invalid records are skipped & printed to `stdout`
+func buildFromDocuments(index *knn.KNN, path, format string, stdout io.Writer) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("unable to read documents due: %w", err)
+	}
+	defer file.Close()
+
+	importDocuments := knnio.ImportJSONL
+	if format == "csv" {
+		importDocuments = knnio.ImportCSV
+	}
+	result, err := importDocuments(index, file, knnio.Options{
+		OnError: func(err *knnio.LineError) {
+			fmt.Fprintf(stdout, "skipped %v\n", err)
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("unable to read documents due: %w", err)
+	}
+	if result.NumFailed > 0 {
+		fmt.Fprintf(stdout, "skipped %v invalid documents\n", result.NumFailed)
+	}
+	return nil
+}
+
+// buildFromVecs builds index from vectors file at `path`, the vector
+// dimension is taken from the file when it is not set in the configs
+func buildFromVecs(f *indexFlags, path string, format knnio.VecsFormat, batchSize int) (*knn.KNN, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read documents due: %w", err)
+	}
+	defer file.Close()
+
+	r := knnio.NewVecsReader(file, format)
+	if f.configs.VectorDimension <= 0 {
+		dim, err := r.Dimension()
+		if err != nil {
+			return nil, fmt.Errorf("unable to read vector dimension due: %w", err)
+		}
+		f.configs.VectorDimension = dim
+	}
+	index, err := f.newIndex()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := knnio.AddVecs(index, r, batchSize); err != nil {
+		return nil, fmt.Errorf("unable to read documents due: %w", err)
+	}
+	return index, nil
+}
+
+// runStats prints statistics of index
+func runStats(args []string, stdout io.Writer) error {
+	fs := newFlagSet("stats", stdout)
+	var f indexFlags
+	f.register(fs)
+	topBuckets := fs.Int("top", 5, "number of the largest buckets to print")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	index, err := f.open(fs)
+	if err != nil {
+		return err
+	}
+	stats := index.Stats()
+	fmt.Fprintf(stdout, "documents:         %v\n", stats.NumDocuments)
+	fmt.Fprintf(stdout, "vector dimension:  %v\n", f.configs.VectorDimension)
+	fmt.Fprintf(stdout, "\nestimated memory:\n")
+	fmt.Fprintf(stdout, "  hash tables:     %v\n", formatBytes(stats.HashTableBytes))
+	fmt.Fprintf(stdout, "  buckets:         %v\n", formatBytes(stats.BucketBytes))
+	fmt.Fprintf(stdout, "  documents:       %v\n", formatBytes(stats.DocumentBytes))
+	fmt.Fprintf(stdout, "  vectors:         %v\n", formatBytes(stats.VectorBytes))
+	fmt.Fprintf(stdout, "  total:           %v\n", formatBytes(stats.TotalBytes))
+	fmt.Fprintf(stdout, "\ntables:\n")
+	for i, table := range stats.Tables {
+		fmt.Fprintf(stdout, "  table %v: %v buckets, largest bucket %v, %v split buckets\n",
+			i, table.NumBuckets, table.LargestBucketSize, table.NumSplitBuckets)
+	}
+	// group bucket sizes into power of 2 ranges
+	buckets := index.OversizedBuckets(1)
+	fmt.Fprintf(stdout, "\nbucket size distribution:\n")
+	var counts []int
+	for _, b := range buckets {
+		i := 0
+		for size := b.Size; size > 1; size /= 2 {
+			i++
+		}
+		for len(counts) <= i {
+			counts = append(counts, 0)
+		}
+		counts[i]++
+	}
+	for i, count := range counts {
+		min, max := 1<<uint(i), 1<<uint(i+1)-1
+		fmt.Fprintf(stdout, "  %-13v %v\n", fmt.Sprintf("%v-%v:", min, max), count)
+	}
+	if *topBuckets > 0 && len(buckets) > 0 {
+		fmt.Fprintf(stdout, "\nlargest buckets:\n")
+		for i := 0; i < *topBuckets && i < len(buckets); i++ {
+			b := buckets[i]
+			fmt.Fprintf(stdout, "  table %v, depth %v: %v documents\n", b.Table, b.Depth, b.Size)
+		}
+	}
+	return nil
+}
+
+// runQuery queries index by vector or id
+func runQuery(args []string, stdout io.Writer) error {
+	fs := newFlagSet("query", stdout)
+	var f indexFlags
+	f.register(fs)
+	vectorStr := fs.String("vector", "", "comma separated values of query vector")
+	docID := fs.String("id", "", "id of document in the index used as query")
+	k := fs.Int("k", 10, "number of returned documents")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if (len(*vectorStr) == 0) == (len(*docID) == 0) {
+		return fmt.Errorf("either -vector or -id must be set")
+	}
+	index, err := f.open(fs)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	var resultDocs []knn.ResultDocument
+	if len(*docID) > 0 {
+		resultDocs, err = index.QueryByID(*docID, *k)
+	} else {
+		var vector []float64
+		vector, err = parseVector(*vectorStr)
+		if err != nil {
+			return err
+		}
+		resultDocs, err = index.Query(vector, *k)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to query due: %w", err)
+	}
+	elapsed := time.Since(start)
+	for i, resultDoc := range resultDocs {
+		fmt.Fprintf(stdout, "%v\t%v\t%.6f\n", i+1, resultID(resultDoc), resultDoc.Distance)
+	}
+	fmt.Fprintf(stdout, "%v results in %v\n", len(resultDocs), elapsed)
+	return nil
+}
+
+// resultID returns id of the document in `resultDoc`, which is
+// either dense or sparse document
+func resultID(resultDoc knn.ResultDocument) string {
+	if resultDoc.Document != nil {
+		return resultDoc.Document.GetID()
+	}
+	return resultDoc.SparseDocument.GetID()
+}
+
+// parseVector parses comma separated values in `s`
+func parseVector(s string) ([]float64, error) {
+	fields := strings.Split(s, ",")
+	vector := make([]float64, 0, len(fields))
+	for _, field := range fields {
+		v, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse vector due: %w", err)
+		}
+		vector = append(vector, v)
+	}
+	return vector, nil
+}
+
+// formatBytes returns human readable form of `n` bytes
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%v B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/riandyrn/go-knn"
+	knnio "github.com/riandyrn/go-knn/io"
+)
+
+// evalResult holds the result of recall evaluation
+type evalResult struct {
+	NumQueries  int
+	K           int
+	Recall      float64
+	MeanLatency time.Duration
+	MaxLatency  time.Duration
+}
+
+// runEval evaluates recall of index against exact search
+func runEval(args []string, stdout io.Writer) error {
+	fs := newFlagSet("eval", stdout)
+	var f indexFlags
+	f.register(fs)
+	queriesPath := fs.String("queries", "", "path of queries file, each line is JSON object with vector field, or vectors file in fvecs/bvecs format, default is sampling the documents in the index")
+	truthPath := fs.String("groundtruth", "", "path of ground truth file in ivecs format for -queries, default is exact search over the index")
+	numSamples := fs.Int("sample", 100, "number of documents sampled as queries when -queries is not set")
+	seed := fs.Int64("seed", 1, "seed for sampling the documents")
+	k := fs.Int("k", 10, "number of returned documents for each query")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *k <= 0 {
+		return fmt.Errorf("value of k must be greater than 0")
+	}
+	if *numSamples <= 0 {
+		return fmt.Errorf("value of sample must be greater than 0")
+	}
+	if len(*truthPath) > 0 && len(*queriesPath) == 0 {
+		return fmt.Errorf("path of queries file must be set through -queries when -groundtruth is set")
+	}
+	index, err := f.open(fs)
+	if err != nil {
+		return err
+	}
+	// prepare queries
+	var queries [][]float64
+	if len(*queriesPath) > 0 {
+		queries, err = readQueries(*queriesPath, f.configs.VectorDimension)
+		if err != nil {
+			return err
+		}
+	} else {
+		queries = sampleQueries(index, *numSamples, *seed)
+	}
+	if len(queries) == 0 {
+		return fmt.Errorf("there is no query to evaluate")
+	}
+	var truth [][]string
+	if len(*truthPath) > 0 {
+		truth, err = readGroundTruth(*truthPath, len(queries), *k)
+		if err != nil {
+			return err
+		}
+	}
+	result, err := evaluate(index, queries, truth, *k)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "queries:       %v\n", result.NumQueries)
+	fmt.Fprintf(stdout, "recall@%-6v %.4f\n", fmt.Sprintf("%v:", result.K), result.Recall)
+	fmt.Fprintf(stdout, "mean latency:  %v\n", result.MeanLatency)
+	fmt.Fprintf(stdout, "max latency:   %v\n", result.MaxLatency)
+	return nil
+}
+
+// evaluate runs `queries` against `index` & compares the results
+// with ids of the nearest documents in `truth`, when `truth` is nil
+// it is computed by exact search over all documents in the index
+func evaluate(index *knn.KNN, queries [][]float64, truth [][]string, k int) (evalResult, error) {
+	// collect documents for exact search
+	var docs []knn.Document
+	if truth == nil {
+		docs = make([]knn.Document, 0, index.Len())
+		index.Range(func(doc knn.Document) bool {
+			docs = append(docs, doc)
+			return true
+		})
+	}
+	result := evalResult{NumQueries: len(queries), K: k}
+	var sumRecall float64
+	var sumLatency time.Duration
+	for i, query := range queries {
+		start := time.Now()
+		resultDocs, err := index.Query(query, k)
+		if err != nil {
+			return evalResult{}, fmt.Errorf("unable to query due: %w", err)
+		}
+		latency := time.Since(start)
+		sumLatency += latency
+		if latency > result.MaxLatency {
+			result.MaxLatency = latency
+		}
+		ids := make([]string, 0, len(resultDocs))
+		for _, resultDoc := range resultDocs {
+			ids = append(ids, resultID(resultDoc))
+		}
+		if truth != nil {
+			sumRecall += calcRecall(truth[i], ids)
+		} else {
+			sumRecall += calcRecall(exactNeighbors(docs, query, k), ids)
+		}
+	}
+	result.Recall = sumRecall / float64(len(queries))
+	result.MeanLatency = sumLatency / time.Duration(len(queries))
+	return result, nil
+}
+
+// calcRecall returns fraction of ids in `truth` which are in `ids`
+func calcRecall(truth, ids []string) float64 {
+	if len(truth) == 0 {
+		return 1
+	}
+	found := map[string]struct{}{}
+	for _, id := range ids {
+		found[id] = struct{}{}
+	}
+	count := 0
+	for _, id := range truth {
+		if _, ok := found[id]; ok {
+			count++
+		}
+	}
+	return float64(count) / float64(len(truth))
+}
+
+// neighbor is document in exact search result
+type neighbor struct {
+	id       string
+	distance float64
+}
+
+// neighborHeap is max-heap of neighbors ordered by distance
+type neighborHeap []neighbor
+
+func (h neighborHeap) Len() int { return len(h) }
+
+func (h neighborHeap) Less(i, j int) bool { return h[i].distance > h[j].distance }
+
+func (h neighborHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *neighborHeap) Push(x interface{}) { *h = append(*h, x.(neighbor)) }
+
+func (h *neighborHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// exactNeighbors returns ids of `k` documents nearest to `query`
+// by comparing all of them, sorted from the nearest
+func exactNeighbors(docs []knn.Document, query []float64, k int) []string {
+	h := make(neighborHeap, 0, k+1)
+	for _, doc := range docs {
+		vector := doc.GetVector()
+		distance := 0.0
+		for i := range vector {
+			d := vector[i] - query[i]
+			distance += d * d
+		}
+		if len(h) < k {
+			heap.Push(&h, neighbor{id: doc.GetID(), distance: distance})
+			continue
+		}
+		if distance < h[0].distance {
+			h[0] = neighbor{id: doc.GetID(), distance: distance}
+			heap.Fix(&h, 0)
+		}
+	}
+	sort.Slice(h, func(i, j int) bool { return h[i].distance < h[j].distance })
+	ids := make([]string, 0, len(h))
+	for _, n := range h {
+		ids = append(ids, n.id)
+	}
+	return ids
+}
+
+// readQueries reads query vectors from JSONL file or vectors file
+// at `path`, the vectors must have dimension `dim`
+func readQueries(path string, dim int) ([][]float64, error) {
+	if filepath.Ext(path) == ".csv" {
+		return nil, fmt.Errorf("queries file in CSV format is not supported, use JSONL or fvecs/bvecs format")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var queries [][]float64
+	if format, err := knnio.VecsFormatOf(path); err == nil {
+		r := knnio.NewVecsReader(f, format)
+		for i := 1; ; i++ {
+			query, err := r.Read()
+			if err == io.EOF {
+				return queries, nil
+			}
+			if err != nil {
+				return nil, fmt.Errorf("unable to read query %v due: %w", i, err)
+			}
+			if len(query) != dim {
+				return nil, fmt.Errorf("unexpected dimension of query %v, expected: %v, got: %v", i, dim, len(query))
+			}
+			queries = append(queries, query)
+		}
+	}
+	dec := json.NewDecoder(bufio.NewReader(f))
+	for i := 1; ; i++ {
+		var query struct {
+			Vector []float64 `json:"vector"`
+		}
+		err := dec.Decode(&query)
+		if err == io.EOF {
+			return queries, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to read query %v due: %w", i, err)
+		}
+		if len(query.Vector) != dim {
+			return nil, fmt.Errorf("unexpected dimension of query %v, expected: %v, got: %v", i, dim, len(query.Vector))
+		}
+		queries = append(queries, query.Vector)
+	}
+}
+
+// readGroundTruth reads ids of the `k` nearest documents for each of
+// `n` queries from ground truth file in ivecs format at `path`, the
+// positions in the file are converted into ids using knnio.VecsID
+func readGroundTruth(path string, n, k int) ([][]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rows, err := knnio.ReadGroundTruth(f)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read ground truth due: %w", err)
+	}
+	if len(rows) != n {
+		return nil, fmt.Errorf("unexpected number of ground truth rows, expected: %v, got: %v", n, len(rows))
+	}
+	truth := make([][]string, 0, n)
+	for i, row := range rows {
+		if len(row) < k {
+			return nil, fmt.Errorf("ground truth of query %v has only %v neighbors, expected at least: %v", i+1, len(row), k)
+		}
+		ids := make([]string, 0, k)
+		for _, pos := range row[:k] {
+			ids = append(ids, knnio.VecsID(pos))
+		}
+		truth = append(truth, ids)
+	}
+	return truth, nil
+}
+
+// sampleQueries returns vectors of maximum `n` random documents
+// in `index`
+func sampleQueries(index *knn.KNN, n int, seed int64) [][]float64 {
+	// reservoir sampling, so we don't need to copy all vectors
+	rnd := rand.New(rand.NewSource(seed))
+	queries := make([][]float64, 0, n)
+	i := 0
+	index.Range(func(doc knn.Document) bool {
+		if len(queries) < n {
+			queries = append(queries, doc.GetVector())
+		} else if j := rnd.Intn(i + 1); j < n {
+			queries[j] = doc.GetVector()
+		}
+		i++
+		return true
+	})
+	return queries
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/riandyrn/go-knn"
+	knnio "github.com/riandyrn/go-knn/io"
+)
+
+// writeDocuments writes `n` random documents with dimension
+// `dim` into documents file at `path`
+func writeDocuments(t *testing.T, path string, n, dim int) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for i := 0; i < n; i++ {
+		vector := make([]float64, dim)
+		for j := range vector {
+			vector[j] = rand.NormFloat64()
+		}
+		enc.Encode(&knn.BasicDocument{ID: fmt.Sprintf("doc_%v", i), Vector: vector})
+	}
+	if err := ioutil.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("unable to write documents due: %v", err)
+	}
+}
+
+// writeVecs writes `n` random vectors with dimension `dim` into fvecs
+// file at `path` & returns them
+func writeVecs(t *testing.T, path string, n, dim int) [][]float64 {
+	var buf bytes.Buffer
+	w := knnio.NewVecsWriter(&buf, knnio.Fvecs)
+	vectors := make([][]float64, n)
+	for i := range vectors {
+		vectors[i] = make([]float64, dim)
+		for j := range vectors[i] {
+			vectors[i][j] = float64(float32(rand.NormFloat64()))
+		}
+		w.Write(vectors[i])
+	}
+	w.Flush()
+	if err := ioutil.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("unable to write vectors due: %v", err)
+	}
+	return vectors
+}
+
+// writeGroundTruth writes positions of `k` nearest `base` vectors for
+// each of `queries` into ivecs file at `path`
+func writeGroundTruth(t *testing.T, path string, base, queries [][]float64, k int) {
+	docs := make([]knn.Document, len(base))
+	for i, vector := range base {
+		docs[i] = &knn.BasicDocument{ID: knnio.VecsID(i), Vector: vector}
+	}
+	var buf bytes.Buffer
+	w := knnio.NewVecsWriter(&buf, knnio.Ivecs)
+	for _, query := range queries {
+		var row []int
+		for _, id := range exactNeighbors(docs, query, k) {
+			pos, _ := strconv.Atoi(id)
+			row = append(row, pos)
+		}
+		w.WriteInts(row)
+	}
+	w.Flush()
+	if err := ioutil.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("unable to write ground truth due: %v", err)
+	}
+}
+
+func TestKnnctl(t *testing.T) {
+	dir, err := ioutil.TempDir("", "knnctl")
+	if err != nil {
+		t.Fatalf("unable to create temp dir due: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	docsPath := filepath.Join(dir, "docs.jsonl")
+	indexPath := filepath.Join(dir, "index.jsonl")
+	writeDocuments(t, docsPath, 200, 4)
+	basePath := filepath.Join(dir, "base.fvecs")
+	vecsIndexPath := filepath.Join(dir, "vecs.jsonl")
+	queryPath := filepath.Join(dir, "query.fvecs")
+	truthPath := filepath.Join(dir, "gt.ivecs")
+	base := writeVecs(t, basePath, 300, 8)
+	queries := writeVecs(t, queryPath, 10, 8)
+	writeGroundTruth(t, truthPath, base, queries, 10)
+	csvPath := filepath.Join(dir, "docs.csv")
+	csvContent := "id,vector\ndoc_1,1 2 3 4\ndoc_2,1 2\ndoc_3,5 6 7 8\n"
+	if err := ioutil.WriteFile(csvPath, []byte(csvContent), 0644); err != nil {
+		t.Fatalf("unable to write csv due: %v", err)
+	}
+	invalidDocsPath := filepath.Join(dir, "invalid.jsonl")
+	invalidContent := `{"id":"doc_1","vector":[1,2,3,4]}` + "\n" + `not json` + "\n" + `{"id":"doc_2","vector":[1,2]}` + "\n" + `{"id":"doc_3","vector":[5,6,7,8]}` + "\n"
+	if err := ioutil.WriteFile(invalidDocsPath, []byte(invalidContent), 0644); err != nil {
+		t.Fatalf("unable to write documents due: %v", err)
+	}
+	sparseIndexPath := filepath.Join(dir, "sparse.jsonl")
+	sparseContent := `{"id":"doc_1","vector":[0,0,0,0]}` + "\n" + `{"id":"sparse_1","indices":[0],"values":[0.5]}` + "\n"
+	if err := ioutil.WriteFile(sparseIndexPath, []byte(sparseContent), 0644); err != nil {
+		t.Fatalf("unable to write sparse index due: %v", err)
+	}
+
+	// use single table with large slot, so the search is exact
+	testCases := []struct {
+		Name      string
+		Args      []string
+		ExpErrNil bool
+		ExpOutput []string
+	}{
+		{
+			Name:      "Test Build",
+			Args:      []string{"build", "-input", docsPath, "-index", indexPath, "-dim", "4", "-tables", "1", "-hyperplanes", "1", "-slot-size", "1000000"},
+			ExpErrNil: true,
+			ExpOutput: []string{"built index of 200 documents"},
+		},
+		{
+			Name:      "Test Build Without Dimension",
+			Args:      []string{"build", "-input", docsPath, "-index", filepath.Join(dir, "other.jsonl")},
+			ExpErrNil: false,
+		},
+		{
+			Name:      "Test Stats",
+			Args:      []string{"stats", "-index", indexPath},
+			ExpErrNil: true,
+			ExpOutput: []string{"documents:         200", "table 0: 1 buckets, largest bucket 200", "128-255:      1"},
+		},
+		{
+			Name:      "Test Query By ID",
+			Args:      []string{"query", "-index", indexPath, "-id", "doc_1", "-k", "3"},
+			ExpErrNil: true,
+			ExpOutput: []string{"3 results"},
+		},
+		{
+			Name:      "Test Query By Vector",
+			Args:      []string{"query", "-index", indexPath, "-vector", "0,0,0,0", "-k", "5"},
+			ExpErrNil: true,
+			ExpOutput: []string{"5 results"},
+		},
+		{
+			Name:      "Test Query Invalid Vector",
+			Args:      []string{"query", "-index", indexPath, "-vector", "0,0"},
+			ExpErrNil: false,
+		},
+		{
+			Name:      "Test Query Sparse Result",
+			Args:      []string{"query", "-index", sparseIndexPath, "-dim", "4", "-tables", "1", "-hyperplanes", "1", "-slot-size", "1000000", "-vector", "0,0,0,0", "-k", "2"},
+			ExpErrNil: true,
+			ExpOutput: []string{"sparse_1", "2 results"},
+		},
+		{
+			Name:      "Test Eval Sparse Result",
+			Args:      []string{"eval", "-index", sparseIndexPath, "-dim", "4", "-tables", "1", "-hyperplanes", "1", "-slot-size", "1000000", "-k", "2"},
+			ExpErrNil: true,
+			ExpOutput: []string{"queries:       1"},
+		},
+		{
+			Name:      "Test Eval Invalid Sample",
+			Args:      []string{"eval", "-index", indexPath, "-sample", "0"},
+			ExpErrNil: false,
+		},
+		{
+			Name:      "Test Eval",
+			Args:      []string{"eval", "-index", indexPath, "-sample", "20", "-k", "5"},
+			ExpErrNil: true,
+			ExpOutput: []string{"queries:       20", "recall@5:     1.0000"},
+		},
+		{
+			Name:      "Test Eval With Queries",
+			Args:      []string{"eval", "-index", indexPath, "-queries", docsPath, "-k", "5"},
+			ExpErrNil: true,
+			ExpOutput: []string{"queries:       200", "recall@5:     1.0000"},
+		},
+		{
+			Name:      "Test Eval With CSV Queries",
+			Args:      []string{"eval", "-index", indexPath, "-queries", csvPath, "-k", "5"},
+			ExpErrNil: false,
+		},
+		{
+			Name:      "Test Eval Lower Recall",
+			Args:      []string{"eval", "-index", indexPath, "-tables", "1", "-hyperplanes", "4", "-slot-size", "1", "-k", "5"},
+			ExpErrNil: true,
+			ExpOutput: []string{"recall@5:     0."},
+		},
+		{
+			Name:      "Test Build From Fvecs",
+			Args:      []string{"build", "-input", basePath, "-index", vecsIndexPath, "-tables", "1", "-hyperplanes", "1", "-slot-size", "1000000", "-batch-size", "64"},
+			ExpErrNil: true,
+			ExpOutput: []string{"built index of 300 documents"},
+		},
+		{
+			Name:      "Test Build From CSV",
+			Args:      []string{"build", "-input", csvPath, "-index", filepath.Join(dir, "csv.jsonl"), "-dim", "4"},
+			ExpErrNil: true,
+			ExpOutput: []string{"skipped line 3:", "skipped 1 invalid documents", "built index of 2 documents"},
+		},
+		{
+			Name:      "Test Build From JSONL With Invalid Lines",
+			Args:      []string{"build", "-input", invalidDocsPath, "-index", filepath.Join(dir, "valid.jsonl"), "-dim", "4"},
+			ExpErrNil: true,
+			ExpOutput: []string{"skipped line 2:", "skipped line 3:", "skipped 2 invalid documents", "built index of 2 documents"},
+		},
+		{
+			Name:      "Test Build Unknown Format",
+			Args:      []string{"build", "-input", basePath, "-index", filepath.Join(dir, "other.jsonl"), "-format", "csv"},
+			ExpErrNil: false,
+		},
+		{
+			Name:      "Test Eval With Ground Truth",
+			Args:      []string{"eval", "-index", vecsIndexPath, "-queries", queryPath, "-groundtruth", truthPath, "-k", "5"},
+			ExpErrNil: true,
+			ExpOutput: []string{"queries:       10", "recall@5:     1.0000"},
+		},
+		{
+			Name:      "Test Eval Ground Truth Without Queries",
+			Args:      []string{"eval", "-index", vecsIndexPath, "-groundtruth", truthPath},
+			ExpErrNil: false,
+		},
+		{
+			Name:      "Test Eval Ground Truth Too Short",
+			Args:      []string{"eval", "-index", vecsIndexPath, "-queries", queryPath, "-groundtruth", truthPath, "-k", "20"},
+			ExpErrNil: false,
+		},
+		{
+			Name:      "Test Unknown Command",
+			Args:      []string{"compact"},
+			ExpErrNil: false,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			err := run(testCase.Args, &stdout)
+			if testCase.ExpErrNil != (err == nil) {
+				t.Fatalf("unexpected error, expected nil: %v, got: %v", testCase.ExpErrNil, err)
+			}
+			for _, exp := range testCase.ExpOutput {
+				if !strings.Contains(stdout.String(), exp) {
+					t.Fatalf("output doesn't contain %q, got:\n%v", exp, stdout.String())
+				}
+			}
+		})
+	}
+}
+
+func TestCalcRecall(t *testing.T) {
+	testCases := []struct {
+		Name      string
+		Truth     []string
+		IDs       []string
+		ExpRecall float64
+	}{
+		{Name: "Test All Found", Truth: []string{"a", "b"}, IDs: []string{"b", "a"}, ExpRecall: 1},
+		{Name: "Test Half Found", Truth: []string{"a", "b"}, IDs: []string{"a", "c"}, ExpRecall: 0.5},
+		{Name: "Test None Found", Truth: []string{"a", "b"}, IDs: nil, ExpRecall: 0},
+		{Name: "Test Empty Truth", Truth: nil, IDs: []string{"a"}, ExpRecall: 1},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			recall := calcRecall(testCase.Truth, testCase.IDs)
+			if recall != testCase.ExpRecall {
+				t.Fatalf("unexpected recall, expected: %v, got: %v", testCase.ExpRecall, recall)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+// Command knnctl builds, inspects & queries KNN index files, so the
+// persisted index could be debugged without writing Go code.
+//
+// Usage:
+//
+//	knnctl build -input docs.jsonl -index index.jsonl -dim 128 [-tables 3 ...]
+//	knnctl build -input docs.csv -index index.jsonl -dim 128 [-tables 3 ...]
+//	knnctl build -input base.fvecs -index index.jsonl [-tables 3 ...]
+//	knnctl stats -index index.jsonl
+//	knnctl query -index index.jsonl -vector 0.1,0.2,... [-k 10]
+//	knnctl query -index index.jsonl -id doc_1 [-k 10]
+//	knnctl eval -index index.jsonl [-queries queries.jsonl | -sample 100] [-k 10]
+//	knnctl eval -index index.jsonl -queries query.fvecs -groundtruth gt.ivecs [-k 10]
+//
+// The index file holds documents written by KNN.Save, each line is
+// JSON object with id, vector & metadata fields. The hash tables are
+// not persisted, they are rebuilt when the index is loaded. The build
+// command writes the index configs to `<index>.configs.json`, the other
+// commands read the configs from it. The configs flags (e.g -tables)
+// override the values in the configs file, so the same index could be
+// inspected using different params.
+//
+// The invalid lines of documents file in JSONL or CSV format are
+// skipped by the build command, each of them is printed with its
+// line number.
+//
+// The build command also accepts vectors file in fvecs or bvecs format
+// (e.g SIFT1M), the id of each document is its position in the file
+// starting from "0", so the ground truth in ivecs format could be used
+// by the eval command.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/riandyrn/go-knn"
+	"github.com/riandyrn/go-knn/internal/configfile"
+	"github.com/riandyrn/go-knn/internal/indexfile"
+)
+
+// command is single subcommand of knnctl
+type command struct {
+	name  string
+	usage string
+	run   func(args []string, stdout io.Writer) error
+}
+
+var commands = []command{
+	{name: "build", usage: "build index file from documents file", run: runBuild},
+	{name: "stats", usage: "print statistics of index file", run: runStats},
+	{name: "query", usage: "query index file by vector or id", run: runQuery},
+	{name: "eval", usage: "evaluate recall of index file against exact search", run: runEval},
+}
+
+func main() {
+	err := run(os.Args[1:], os.Stdout)
+	if err == flag.ErrHelp {
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "knnctl: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run executes the subcommand in `args` & writes its output to `stdout`
+func run(args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		printUsage(stdout)
+		return fmt.Errorf("missing command")
+	}
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:], stdout)
+		}
+	}
+	printUsage(stdout)
+	return fmt.Errorf("unknown command: %v", args[0])
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintf(w, "usage: %v <command> [flags]\n\ncommands:\n", filepath.Base(os.Args[0]))
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-8v %v\n", cmd.name, cmd.usage)
+	}
+	fmt.Fprintf(w, "\nrun `%v <command> -h` for the flags of command\n", filepath.Base(os.Args[0]))
+}
+
+// indexFlags holds the flags for opening index file, they are
+// shared by the commands
+type indexFlags struct {
+	path       string
+	configPath string
+	configs    configfile.Configs
+}
+
+func (f *indexFlags) register(fs *flag.FlagSet) {
+	f.configs = configfile.Default()
+	fs.StringVar(&f.path, "index", "", "path of index file")
+	fs.StringVar(&f.configPath, "config", "", "path of configs file, default is the configs file of the index")
+	f.configs.RegisterFlags(fs)
+}
+
+// loadConfigs reads configs from the configs file, the default
+// configs file is ignored when it doesn't exist
+func (f *indexFlags) loadConfigs(fs *flag.FlagSet) error {
+	path := f.configPath
+	if len(path) == 0 {
+		if len(f.path) == 0 {
+			return nil
+		}
+		path = indexfile.ConfigsPath(f.path)
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return nil
+		}
+	}
+	return f.configs.Load(fs, path)
+}
+
+// open loads the index file using the configs from flags
+func (f *indexFlags) open(fs *flag.FlagSet) (*knn.KNN, error) {
+	if len(f.path) == 0 {
+		return nil, fmt.Errorf("path of index file must be set through -index")
+	}
+	if err := f.loadConfigs(fs); err != nil {
+		return nil, fmt.Errorf("unable to read configs due: %w", err)
+	}
+	if f.configs.VectorDimension <= 0 {
+		return nil, fmt.Errorf("vector dimension must be set through -dim or configs file")
+	}
+	index, err := f.newIndex()
+	if err != nil {
+		return nil, err
+	}
+	if err := indexfile.Load(index, f.path); err != nil {
+		return nil, fmt.Errorf("unable to load index due: %w", err)
+	}
+	return index, nil
+}
+
+// newIndex returns empty index using the configs from flags
+func (f *indexFlags) newIndex() (*knn.KNN, error) {
+	configs := f.configs.KNNConfigs()
+	if err := configs.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configs due: %w", err)
+	}
+	return knn.NewKNN(configs), nil
+}
+
+// newFlagSet returns flag set for command with `name`, the
+// flag errors are returned instead of exiting
+func newFlagSet(name string, stdout io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	return fs
+}
@@ -1,5 +1,10 @@
 package knn
 
+import (
+	"fmt"
+	"time"
+)
+
 // Configs holds configuration for KNN
 type Configs struct {
 	// VectorDimension represents expected number of
@@ -30,4 +35,169 @@ type Configs struct {
 	// Checkout https://github.com/ekzhu/lsh/issues/2 for details. This
 	// parameter refers to `w` parameter mentioned on the issue.
 	SlotSize int
+
+	// SparseProjection when set to true makes the index generates
+	// its hyperplanes on the fly instead of storing them in memory.
+	// Storing the hyperplanes takes NumHashTable * NumHyperplane *
+	// VectorDimension floats, which is not feasible for very high
+	// dimensional sparse vectors (e.g TF-IDF features).
+	SparseProjection bool
+
+	// NumWorkers represents number of goroutines used for updating
+	// the hash tables in parallel on Add & Delete. The value less
+	// than 2 means the tables are updated sequentially, which is
+	// usually faster for small NumHashTable. When it is set, call
+	// Close() once the index is no longer used to stop the workers.
+	NumWorkers int
+
+	// Quantizer is optional, when set the index stores int8 codes
+	// of document vectors & use them for the first-pass ranking of
+	// LSH candidates. Use TrainScalarQuantizer() to build it from
+	// sample of your vectors. The quantizer must has the same
+	// dimension as VectorDimension.
+	//
+	// Without RescoreSize the index only keeps the codes instead of
+	// the added documents, so the vectors take 1 byte per dimension
+	// instead of 8. The documents returned by the index hold the id,
+	// the metadata (check MetadataDocument) & the vector decoded from
+	// the codes, which is only approximation of the original vector.
+	Quantizer *ScalarQuantizer
+
+	// RescoreSize represents number of top candidates from the
+	// quantized ranking which distance will be recalculated using
+	// exact document vectors. The value of 0 means no rescoring,
+	// so the returned distances are approximations. Only used when
+	// Quantizer is set.
+	//
+	// Since the exact vectors are needed, the index keeps the added
+	// documents along with the codes. So the memory saving only
+	// happens when the documents don't keep their own float vectors
+	// (e.g they load it lazily when GetVector is called).
+	RescoreSize int
+
+	// MemoryLimit represents the maximum estimated memory usage of
+	// the index in bytes. When adding document would exceed it, Add
+	// returns ErrMemoryLimit. The value of 0 means no limit. Checkout
+	// KNN.Stats() for the estimated memory usage.
+	MemoryLimit int64
+
+	// Capacity represents the maximum number of documents in the
+	// index. When adding new document to the full index, the document
+	// chosen by EvictionPolicy is evicted to make a room for it. The
+	// value of 0 means no limit.
+	Capacity int
+
+	// EvictionPolicy represents policy for choosing the evicted
+	// document, the default is EvictLRU. Document is used when it
+	// is returned by Get or appears in Query results. Only used when
+	// Capacity is set.
+	EvictionPolicy EvictionPolicy
+
+	// OnEvict is optional, it is called with id of every evicted
+	// document after the eviction. It is called outside of the lock,
+	// so it could access the index.
+	OnEvict func(docID string)
+
+	// SnapshotIsolation when set to true makes queries run against
+	// immutable snapshot of the index, so they never block on writes.
+	// The writes only become visible after Commit() is called, or
+	// after the next PublishInterval tick. Every commit copies the hash
+	// tables & the document handles (but not the documents), so it
+	// takes time proportional to the index size & the snapshot counts
+	// toward MemoryLimit. Use longer PublishInterval for large index.
+	SnapshotIsolation bool
+
+	// PublishInterval represents interval for committing the writes
+	// automatically in snapshot isolation mode. The value of 0 means
+	// the writes are only published by calling Commit().
+	PublishInterval time.Duration
+
+	// SweepInterval represents interval for removing expired documents
+	// from the index (check AddWithTTL & Expirer). The expired documents
+	// are never returned by the index, but they still take memory until
+	// they are swept. The value of 0 means the expired documents are only
+	// removed by calling DeleteExpired(). When it is set, call Close()
+	// once the index is no longer used to stop the sweeping.
+	SweepInterval time.Duration
+
+	// MaxBucketSize represents the maximum number of documents in
+	// single bucket. When a bucket grows beyond it, its documents are
+	// re-hashed using NumSplitHyperplane extra hyperplanes into a
+	// local sub-table, so queries landing on it compare fewer
+	// candidates. The query probes the sub-buckets nearest to it
+	// until 4 times MaxBucketSize candidates are found, so the
+	// neighbours in the sibling sub-buckets are not missed. This is
+	// useful for clustered data where few buckets hold large share
+	// of the documents. Bucket of identical (or very close) vectors
+	// might still exceed it, since a bucket is split at most 4 times.
+	// The value of 0 means buckets are never split.
+	// Checkout KNN.OversizedBuckets() for finding the large buckets.
+	MaxBucketSize int
+
+	// NumSplitHyperplane represents number of extra hyperplanes used
+	// for splitting single bucket, the default value is 2. Only used
+	// when MaxBucketSize is set.
+	NumSplitHyperplane int
+}
+
+// Validate returns error when the configs are invalid, for example
+// when the dimension of Quantizer doesn't match VectorDimension. It
+// should be called before NewKNN for the configs which come from
+// untrusted source, since NewKNN doesn't validate them.
+func (c Configs) Validate() error {
+	if c.VectorDimension <= 0 {
+		return fmt.Errorf("VectorDimension must be greater than 0")
+	}
+	if err := c.validateLsh(); err != nil {
+		return err
+	}
+	if c.Quantizer != nil && c.Quantizer.Dimension() != c.VectorDimension {
+		return fmt.Errorf("unexpected quantizer dimension due: %w", &DimensionError{
+			Expected: c.VectorDimension,
+			Got:      c.Quantizer.Dimension(),
+		})
+	}
+	return nil
+}
+
+// validateLsh returns error when the LSH params are invalid
+func (c Configs) validateLsh() error {
+	if c.NumHashTable <= 0 || c.NumHyperplane <= 0 || c.SlotSize <= 0 {
+		return fmt.Errorf("NumHashTable, NumHyperplane & SlotSize must be greater than 0")
+	}
+	return nil
+}
+
+// BinaryConfigs holds configuration for BinaryKNN
+type BinaryConfigs struct {
+	// NumBits represents number of bits in the input vector,
+	// for example 64-bit perceptual hash has NumBits 64. The
+	// input vector must has (NumBits + 63) / 64 words.
+	NumBits int
+
+	// NumHashTable represents number of hash tables exists
+	// on memory. Just like in KNN, using more tables lowers
+	// the index chance to miss near duplicates.
+	NumHashTable int
+
+	// NumSampledBits represents number of bits sampled from
+	// input vector to form the hash key in single table. The
+	// higher the value, the fewer candidates will be compared
+	// in each table. The maximum value is 64.
+	NumSampledBits int
+}
+
+// MinHashConfigs holds configuration for MinHashKNN
+type MinHashConfigs struct {
+	// NumBand represents number of bands in the MinHash signature,
+	// each band has its own hash table. Two documents become
+	// candidates when all rows in at least one band are equal, so
+	// more bands means lower chance to miss similar documents.
+	NumBand int
+
+	// NumRow represents number of signature values in single band.
+	// More rows means fewer dissimilar candidates in each band. The
+	// similarity threshold where documents are likely to become
+	// candidates is roughly (1/NumBand)^(1/NumRow).
+	NumRow int
 }
@@ -1,5 +1,7 @@
 package knn
 
+import "time"
+
 // Document represents single entry in KNN index
 type Document interface {
 	GetID() string
@@ -10,6 +12,59 @@ type Document interface {
 // extra information related to search result
 // (e.g similarity distance)
 type ResultDocument struct {
-	Document Document
-	Distance float64 // the lower the better
+	Document       Document
+	SparseDocument SparseDocument // set instead of Document for sparse entry
+	Distance       float64        // the lower the better
+}
+
+// SparseDocument represents single entry in KNN index which
+// vector is sparse. The vector is represented by indices of its
+// non-zero values in ascending order & the values themselves.
+type SparseDocument interface {
+	GetID() string
+	GetSparseVector() (indices []int, values []float64)
+}
+
+// Expirer could be implemented by Document or SparseDocument to
+// make it expire at certain time. The expired document is no longer
+// returned by the index, it is removed from the index on the next
+// sweep. Zero time means the document never expires.
+type Expirer interface {
+	GetExpiry() time.Time
+}
+
+// MetadataDocument could be implemented by Document or SparseDocument
+// which carries metadata, the metadata is kept when the document is
+// replicated or exported.
+type MetadataDocument interface {
+	GetMetadata() map[string]interface{}
+}
+
+// BasicDocument is simple implementation of Document, it is used
+// by the index when it needs to reconstruct document (e.g on the
+// replication follower).
+type BasicDocument struct {
+	ID       string                 `json:"id"`
+	Vector   []float64              `json:"vector"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
 }
+
+func (d *BasicDocument) GetID() string { return d.ID }
+
+func (d *BasicDocument) GetVector() []float64 { return d.Vector }
+
+func (d *BasicDocument) GetMetadata() map[string]interface{} { return d.Metadata }
+
+// BasicSparseDocument is simple implementation of SparseDocument
+type BasicSparseDocument struct {
+	ID       string                 `json:"id"`
+	Indices  []int                  `json:"indices"`
+	Values   []float64              `json:"values"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+func (d *BasicSparseDocument) GetID() string { return d.ID }
+
+func (d *BasicSparseDocument) GetSparseVector() ([]int, []float64) { return d.Indices, d.Values }
+
+func (d *BasicSparseDocument) GetMetadata() map[string]interface{} { return d.Metadata }
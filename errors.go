@@ -0,0 +1,76 @@
+package knn
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The errors below could be checked using errors.Is, the validation
+// errors returned by the index wrap them with more details.
+var (
+	// ErrNotFound is returned by Get & Delete when the document
+	// is not found in the index.
+	ErrNotFound = errors.New("document not found")
+
+	// ErrInvalidDocument is returned when the document is nil or
+	// has empty id, vector or set.
+	ErrInvalidDocument = errors.New("invalid document")
+
+	// ErrDimensionMismatch is returned when the dimension of input
+	// vector is different from the index. For dense vector the
+	// returned error is *DimensionError.
+	ErrDimensionMismatch = errors.New("vector dimension mismatch")
+
+	// ErrEmptyVector is returned when the input vector is empty
+	ErrEmptyVector = errors.New("vector must not empty")
+
+	// ErrInvalidVector is returned when the input sparse vector
+	// is malformed, e.g its indices are not ascending.
+	ErrInvalidVector = errors.New("invalid vector")
+
+	// ErrInvalidK is returned when the value of k is less than 1
+	ErrInvalidK = errors.New("value of k must be greater than 0")
+
+	// ErrLogTruncated is returned by Leader when the mutations
+	// requested by the follower are no longer in the log, the
+	// follower needs to start over from offset 0.
+	ErrLogTruncated = errors.New("replication log truncated")
+
+	// ErrMemoryLimit is returned by Add when adding the document
+	// would make the estimated memory usage exceeds MemoryLimit.
+	ErrMemoryLimit = errors.New("memory limit exceeded")
+)
+
+// DimensionError is returned when the dimension of input vector is
+// different from the expected dimension. It matches ErrDimensionMismatch
+// on errors.Is, use errors.As to get the dimensions.
+type DimensionError struct {
+	Expected int
+	Got      int
+}
+
+func (e *DimensionError) Error() string {
+	return fmt.Sprintf("unexpected vector dimension, expected: %v, got: %v", e.Expected, e.Got)
+}
+
+// Is makes the error matches ErrDimensionMismatch
+func (e *DimensionError) Is(target error) bool {
+	return target == ErrDimensionMismatch
+}
+
+// BatchError is returned by AddBatch when one of the documents fails,
+// it wraps the error of the document at Index. When the error is
+// ErrMemoryLimit, the documents before Index are already added,
+// otherwise none of the documents is added.
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("unable to add document at %v due: %v", e.Index, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
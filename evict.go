@@ -0,0 +1,197 @@
+package knn
+
+import (
+	"container/heap"
+	"container/list"
+	"sync"
+)
+
+// EvictionPolicy represents policy for choosing which document is
+// evicted when the index reaches its Capacity
+type EvictionPolicy int
+
+const (
+	// EvictLRU evicts the least recently used document
+	EvictLRU EvictionPolicy = iota
+	// EvictLFU evicts the least frequently used document, the ties
+	// are broken by evicting the least recently used one
+	EvictLFU
+)
+
+// evictor tracks usage of document handles for choosing the
+// eviction victim. It has its own lock because the usage is
+// also updated by reads which only hold the read lock of KNN.
+type evictor struct {
+	policy EvictionPolicy
+
+	// tick is logical clock used for ordering the usages
+	tick uint64
+
+	// ids holds document id of the tracked handles, the usage is
+	// only updated when the id matches. So the reads on a stale
+	// snapshot don't touch handle which is reused by other document.
+	ids map[uint32]string
+
+	// lru holds the handles from the most recently used,
+	// it is used by EvictLRU
+	lru     *list.List
+	lruElms map[uint32]*list.Element
+
+	// lfu is min-heap of handles usage, it is used by EvictLFU
+	lfu      lfuHeap
+	lfuItems map[uint32]*lfuItem
+
+	mux sync.Mutex
+}
+
+// lfuItem holds usage of single handle
+type lfuItem struct {
+	handle uint32
+	freq   uint64
+	tick   uint64
+	index  int
+}
+
+func newEvictor(policy EvictionPolicy) *evictor {
+	e := &evictor{policy: policy}
+	e.reset()
+	return e
+}
+
+// add starts tracking handle `h` of document with `id`
+func (e *evictor) add(h uint32, id string) {
+	if e == nil {
+		return
+	}
+	// acquire lock
+	e.mux.Lock()
+	// defer unlock
+	defer e.mux.Unlock()
+
+	e.ids[h] = id
+	e.tick++
+	if e.policy == EvictLFU {
+		item := &lfuItem{handle: h, freq: 1, tick: e.tick}
+		e.lfuItems[h] = item
+		heap.Push(&e.lfu, item)
+		return
+	}
+	e.lruElms[h] = e.lru.PushFront(h)
+}
+
+// touch marks handle `h` of document with `id` as used, it is
+// ignored when the handle is untracked or held by other document
+func (e *evictor) touch(h uint32, id string) {
+	if e == nil {
+		return
+	}
+	// acquire lock
+	e.mux.Lock()
+	// defer unlock
+	defer e.mux.Unlock()
+
+	if trackedID, ok := e.ids[h]; !ok || trackedID != id {
+		return
+	}
+	e.tick++
+	if e.policy == EvictLFU {
+		item := e.lfuItems[h]
+		item.freq++
+		item.tick = e.tick
+		heap.Fix(&e.lfu, item.index)
+		return
+	}
+	e.lru.MoveToFront(e.lruElms[h])
+}
+
+// remove stops tracking handle `h`
+func (e *evictor) remove(h uint32) {
+	if e == nil {
+		return
+	}
+	// acquire lock
+	e.mux.Lock()
+	// defer unlock
+	defer e.mux.Unlock()
+
+	delete(e.ids, h)
+	if e.policy == EvictLFU {
+		if item, ok := e.lfuItems[h]; ok {
+			heap.Remove(&e.lfu, item.index)
+			delete(e.lfuItems, h)
+		}
+		return
+	}
+	if elm, ok := e.lruElms[h]; ok {
+		e.lru.Remove(elm)
+		delete(e.lruElms, h)
+	}
+}
+
+// victim returns handle which should be evicted next, it returns
+// false when there is no tracked handle
+func (e *evictor) victim() (uint32, bool) {
+	// acquire lock
+	e.mux.Lock()
+	// defer unlock
+	defer e.mux.Unlock()
+
+	if e.policy == EvictLFU {
+		if len(e.lfu) == 0 {
+			return 0, false
+		}
+		return e.lfu[0].handle, true
+	}
+	if e.lru.Len() == 0 {
+		return 0, false
+	}
+	return e.lru.Back().Value.(uint32), true
+}
+
+// reset stops tracking all handles
+func (e *evictor) reset() {
+	if e == nil {
+		return
+	}
+	// acquire lock
+	e.mux.Lock()
+	// defer unlock
+	defer e.mux.Unlock()
+
+	e.ids = map[uint32]string{}
+	e.lru = list.New()
+	e.lruElms = map[uint32]*list.Element{}
+	e.lfu = nil
+	e.lfuItems = map[uint32]*lfuItem{}
+}
+
+// lfuHeap is min-heap of usages ordered by frequency then tick
+type lfuHeap []*lfuItem
+
+func (h lfuHeap) Len() int { return len(h) }
+
+func (h lfuHeap) Less(i, j int) bool {
+	if h[i].freq != h[j].freq {
+		return h[i].freq < h[j].freq
+	}
+	return h[i].tick < h[j].tick
+}
+
+func (h lfuHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *lfuHeap) Push(x interface{}) {
+	item := x.(*lfuItem)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *lfuHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
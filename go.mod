@@ -1,5 +1,3 @@
 module github.com/riandyrn/go-knn
 
-go 1.12
-
-require github.com/riandyrn/lsh v1.1.2
+go 1.13
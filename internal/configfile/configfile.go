@@ -0,0 +1,110 @@
+// Package configfile holds JSON form of knn.Configs shared by the
+// commands, the configs could be read from file & overridden by
+// command line flags.
+package configfile
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/riandyrn/go-knn"
+)
+
+// Configs is the JSON form of knn.Configs, it only holds the
+// params which could be represented in JSON
+type Configs struct {
+	VectorDimension    int   `json:"vector_dimension"`
+	NumHashTable       int   `json:"num_hash_table"`
+	NumHyperplane      int   `json:"num_hyperplane"`
+	SlotSize           int   `json:"slot_size"`
+	SparseProjection   bool  `json:"sparse_projection,omitempty"`
+	NumWorkers         int   `json:"num_workers,omitempty"`
+	MemoryLimit        int64 `json:"memory_limit,omitempty"`
+	Capacity           int   `json:"capacity,omitempty"`
+	MaxBucketSize      int   `json:"max_bucket_size,omitempty"`
+	NumSplitHyperplane int   `json:"num_split_hyperplane,omitempty"`
+}
+
+// Default returns configs with the default hash table params
+func Default() Configs {
+	return Configs{NumHashTable: 3, NumHyperplane: 3, SlotSize: 5}
+}
+
+// KNNConfigs returns knn.Configs holding the params of `c`
+func (c Configs) KNNConfigs() knn.Configs {
+	return knn.Configs{
+		VectorDimension:    c.VectorDimension,
+		NumHashTable:       c.NumHashTable,
+		NumHyperplane:      c.NumHyperplane,
+		SlotSize:           c.SlotSize,
+		SparseProjection:   c.SparseProjection,
+		NumWorkers:         c.NumWorkers,
+		MemoryLimit:        c.MemoryLimit,
+		Capacity:           c.Capacity,
+		MaxBucketSize:      c.MaxBucketSize,
+		NumSplitHyperplane: c.NumSplitHyperplane,
+	}
+}
+
+// RegisterFlags defines flags in `fs` for setting the params of `c`,
+// the current values of `c` are used as the default values
+func (c *Configs) RegisterFlags(fs *flag.FlagSet) {
+	fs.IntVar(&c.VectorDimension, "dim", c.VectorDimension, "vector dimension")
+	fs.IntVar(&c.NumHashTable, "tables", c.NumHashTable, "number of hash tables")
+	fs.IntVar(&c.NumHyperplane, "hyperplanes", c.NumHyperplane, "number of hyperplanes in single table")
+	fs.IntVar(&c.SlotSize, "slot-size", c.SlotSize, "slot size of hyperplanes")
+	fs.IntVar(&c.NumWorkers, "workers", c.NumWorkers, "number of workers for updating the tables")
+	fs.Int64Var(&c.MemoryLimit, "memory-limit", c.MemoryLimit, "maximum estimated memory usage in bytes, 0 means no limit")
+	fs.IntVar(&c.Capacity, "capacity", c.Capacity, "maximum number of documents, 0 means no limit")
+	fs.IntVar(&c.MaxBucketSize, "max-bucket-size", c.MaxBucketSize, "maximum number of documents in single bucket, 0 means no splitting")
+}
+
+// Load reads configs in JSON file at `path` into `c`, then applies
+// the flags in `fs` which are set explicitly on top of it. So the
+// flags always override the values in the file. `fs` must already
+// be parsed.
+func (c *Configs) Load(fs *flag.FlagSet, path string) error {
+	// keep the flags which are set explicitly, since
+	// reading the file overwrites their values
+	setFlags := map[string]string{}
+	fs.Visit(func(f *flag.Flag) {
+		setFlags[f.Name] = f.Value.String()
+	})
+	if err := Read(path, c); err != nil {
+		return err
+	}
+	for name, value := range setFlags {
+		if err := fs.Set(name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Read reads configs in JSON file at `path` into `c`
+func Read(path string, c *Configs) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(c); err != nil {
+		return fmt.Errorf("unable to decode %v due: %w", path, err)
+	}
+	return nil
+}
+
+// Write writes `c` as JSON into file at `path`
+func Write(path string, c Configs) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
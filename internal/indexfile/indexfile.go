@@ -0,0 +1,50 @@
+// Package indexfile reads & writes the documents of KNN index from
+// & to file, it is shared by the commands. The file holds the
+// documents written by KNN.Save, the configs of the index are
+// kept in separate JSON file next to it (check ConfigsPath).
+package indexfile
+
+import (
+	"os"
+
+	"github.com/riandyrn/go-knn"
+)
+
+// ConfigsPath returns path of configs file of index file at `path`
+func ConfigsPath(path string) string {
+	return path + ".configs.json"
+}
+
+// Load loads documents from file at `path` into `index`
+func Load(index *knn.KNN, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return index.Load(f)
+}
+
+// Save saves documents of `index` into file at `path`, the documents
+// are written into temporary file first, so the existing file is
+// never left partially written
+func Save(index *knn.KNN, path string) error {
+	tmpPath := path + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	if err := index.Save(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
@@ -0,0 +1,240 @@
+package knnio
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/riandyrn/go-knn"
+)
+
+// csvColumns holds the column positions of mapped fields
+type csvColumns struct {
+	id       int
+	vector   []int
+	metadata []int
+}
+
+// newCSVColumns returns positions of the fields in `opts` within
+// `header`, all of the fields must exist in the header
+func newCSVColumns(header []string, opts Options) (csvColumns, error) {
+	positions := map[string]int{}
+	for i, name := range header {
+		positions[name] = i
+	}
+	find := func(name string) (int, error) {
+		i, ok := positions[name]
+		if !ok {
+			return 0, fmt.Errorf("missing column %q in header", name)
+		}
+		return i, nil
+	}
+	var c csvColumns
+	var err error
+	if c.id, err = find(opts.IDField); err != nil {
+		return csvColumns{}, err
+	}
+	vectorFields := opts.VectorFields
+	if len(vectorFields) == 0 {
+		vectorFields = []string{opts.VectorField}
+	}
+	for _, name := range vectorFields {
+		i, err := find(name)
+		if err != nil {
+			return csvColumns{}, err
+		}
+		c.vector = append(c.vector, i)
+	}
+	for _, name := range opts.MetadataFields {
+		i, err := find(name)
+		if err != nil {
+			return csvColumns{}, err
+		}
+		c.metadata = append(c.metadata, i)
+	}
+	return c, nil
+}
+
+// ImportCSV adds documents from CSV in `r` to `index`. The first
+// record is the header, the columns of id, vector & metadata are
+// located by their names (check Options). The documents are added as
+// knn.BasicDocument. The invalid records (e.g unparseable value or
+// vector with dimension other than Configs.VectorDimension) are
+// skipped & reported in the result. The records are read one by one,
+// so only single batch of documents is held in memory.
+//
+// It only returns error when the header is invalid, reading from `r`
+// fails or the index returns knn.ErrMemoryLimit, the documents before
+// it are already added.
+func ImportCSV(index *knn.KNN, r io.Reader, opts Options) (ImportResult, error) {
+	opts = opts.withDefaults()
+	cr := csv.NewReader(r)
+	cr.Comma = opts.Comma
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+	header, err := cr.Read()
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("unable to read header due: %w", err)
+	}
+	columns, err := newCSVColumns(header, opts)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	im := newImporter(index, opts)
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			im.fail(line, fmt.Errorf("%w: %v", knn.ErrInvalidDocument, parseErr.Err))
+			continue
+		}
+		if err != nil {
+			return im.result, fmt.Errorf("unable to read line %v due: %w", line, err)
+		}
+		doc, err := parseCSVDocument(record, columns, opts)
+		if err != nil {
+			im.fail(line, err)
+			continue
+		}
+		if err := im.add(line, doc); err != nil {
+			return im.result, err
+		}
+	}
+	if err := im.flush(); err != nil {
+		return im.result, err
+	}
+	return im.result, nil
+}
+
+// parseCSVDocument parses single CSV record into document
+func parseCSVDocument(record []string, columns csvColumns, opts Options) (*knn.BasicDocument, error) {
+	field := func(i int) (string, error) {
+		if i >= len(record) {
+			return "", fmt.Errorf("%w: expected at least %v fields, got: %v", knn.ErrInvalidDocument, i+1, len(record))
+		}
+		return record[i], nil
+	}
+	id, err := field(columns.id)
+	if err != nil {
+		return nil, err
+	}
+	// collect vector values
+	var values []string
+	if len(opts.VectorFields) == 0 {
+		s, err := field(columns.vector[0])
+		if err != nil {
+			return nil, err
+		}
+		if opts.VectorSeparator == " " {
+			values = strings.Fields(s)
+		} else {
+			values = strings.Split(s, opts.VectorSeparator)
+		}
+	} else {
+		for _, i := range columns.vector {
+			s, err := field(i)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, s)
+		}
+	}
+	vector := make([]float64, len(values))
+	for i, s := range values {
+		v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid vector value at %v: %v", knn.ErrInvalidDocument, i, err)
+		}
+		vector[i] = v
+	}
+	doc := &knn.BasicDocument{ID: id, Vector: vector}
+	if len(columns.metadata) > 0 {
+		doc.Metadata = make(map[string]interface{}, len(columns.metadata))
+		for j, i := range columns.metadata {
+			s, err := field(i)
+			if err != nil {
+				return nil, err
+			}
+			doc.Metadata[opts.MetadataFields[j]] = s
+		}
+	}
+	return doc, nil
+}
+
+// ExportCSV writes the dense documents in `index` to `w` as CSV which
+// could be imported back by ImportCSV using the same `opts`. The
+// metadata values are written using their default format & missing
+// values are written as empty string. The expired documents are not
+// exported. The documents are written as they are visited by
+// knn.KNN.Range, so the index is read locked until all of them are
+// written to `w`.
+func ExportCSV(index *knn.KNN, w io.Writer, opts Options) error {
+	opts = opts.withDefaults()
+	cw := csv.NewWriter(w)
+	cw.Comma = opts.Comma
+	// write header
+	header := []string{opts.IDField}
+	if len(opts.VectorFields) > 0 {
+		header = append(header, opts.VectorFields...)
+	} else {
+		header = append(header, opts.VectorField)
+	}
+	header = append(header, opts.MetadataFields...)
+	if err := cw.Write(header); err != nil {
+		return fmt.Errorf("unable to export header due: %w", err)
+	}
+	// write documents
+	var err error
+	record := make([]string, 0, len(header))
+	index.Range(func(doc knn.Document) bool {
+		record, err = appendCSVRecord(record[:0], doc, opts)
+		if err == nil {
+			err = cw.Write(record)
+		}
+		if err != nil {
+			err = fmt.Errorf("unable to export document %v due: %w", doc.GetID(), err)
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// appendCSVRecord appends the fields of `doc` to `record`
+func appendCSVRecord(record []string, doc knn.Document, opts Options) ([]string, error) {
+	vector := doc.GetVector()
+	record = append(record, doc.GetID())
+	if len(opts.VectorFields) > 0 {
+		if len(vector) != len(opts.VectorFields) {
+			return nil, &knn.DimensionError{Expected: len(opts.VectorFields), Got: len(vector)}
+		}
+		for _, v := range vector {
+			record = append(record, strconv.FormatFloat(v, 'g', -1, 64))
+		}
+	} else {
+		values := make([]string, len(vector))
+		for i, v := range vector {
+			values[i] = strconv.FormatFloat(v, 'g', -1, 64)
+		}
+		record = append(record, strings.Join(values, opts.VectorSeparator))
+	}
+	metadata := metadataOf(doc)
+	for _, name := range opts.MetadataFields {
+		value := ""
+		if v, ok := metadata[name]; ok && v != nil {
+			value = fmt.Sprint(v)
+		}
+		record = append(record, value)
+	}
+	return record, nil
+}
@@ -0,0 +1,189 @@
+package knnio
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/riandyrn/go-knn"
+)
+
+// Options holds the params for importing & exporting documents in
+// JSONL or CSV. For JSONL the fields are the keys of JSON object on
+// each line, for CSV they are the column names in the header.
+type Options struct {
+	// IDField is the field of document id, default is "id"
+	IDField string
+
+	// VectorField is the field of document vector, default is
+	// "vector". In JSONL it is array of numbers, in CSV it is the
+	// values separated by VectorSeparator.
+	VectorField string
+
+	// VectorFields are the CSV columns holding each value of the
+	// vector in order, they are used instead of VectorField when set
+	VectorFields []string
+
+	// VectorSeparator separates the values in VectorField of CSV,
+	// default is single space
+	VectorSeparator string
+
+	// MetadataField is the JSONL field holding the metadata object,
+	// default is "metadata"
+	MetadataField string
+
+	// MetadataFields are the CSV columns stored as metadata, the
+	// values are stored as string
+	MetadataFields []string
+
+	// Comma is the CSV field delimiter, default is ','
+	Comma rune
+
+	// BatchSize is the number of documents added in single call to
+	// KNN.AddBatch on import, default is 1000
+	BatchSize int
+
+	// OnError is called for each invalid line on import, the
+	// import continues to the next line after it
+	OnError func(err *LineError)
+}
+
+// withDefaults returns copy of `o` with the default values set
+func (o Options) withDefaults() Options {
+	if len(o.IDField) == 0 {
+		o.IDField = "id"
+	}
+	if len(o.VectorField) == 0 {
+		o.VectorField = "vector"
+	}
+	if len(o.VectorSeparator) == 0 {
+		o.VectorSeparator = " "
+	}
+	if len(o.MetadataField) == 0 {
+		o.MetadataField = "metadata"
+	}
+	if o.Comma == 0 {
+		o.Comma = ','
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = 1000
+	}
+	return o
+}
+
+// LineError is the error of single line on import
+type LineError struct {
+	// Line is the line number starting from 1, for CSV it is the
+	// record number including the header which is the same as the
+	// line number unless the fields contain line breaks
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %v: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// maxResultErrors is the maximum number of errors kept in
+// ImportResult, so importing bad file doesn't use unbounded memory
+const maxResultErrors = 100
+
+// ImportResult holds the result of import
+type ImportResult struct {
+	NumAdded  int
+	NumFailed int
+
+	// Errors holds the errors of the first 100 invalid lines, use
+	// Options.OnError for receiving all of them
+	Errors []*LineError
+}
+
+// importer adds parsed documents to index in batches, the number of
+// buffered documents is bounded by the batch size
+type importer struct {
+	index  *knn.KNN
+	opts   Options
+	result ImportResult
+	docs   []knn.Document
+	lines  []int
+}
+
+func newImporter(index *knn.KNN, opts Options) *importer {
+	return &importer{
+		index: index,
+		opts:  opts,
+		docs:  make([]knn.Document, 0, opts.BatchSize),
+		lines: make([]int, 0, opts.BatchSize),
+	}
+}
+
+// add buffers `doc` parsed from `line`, the buffered documents are
+// added to the index once the batch is full
+func (im *importer) add(line int, doc knn.Document) error {
+	im.docs = append(im.docs, doc)
+	im.lines = append(im.lines, line)
+	if len(im.docs) < im.opts.BatchSize {
+		return nil
+	}
+	return im.flush()
+}
+
+// fail records error of `line`
+func (im *importer) fail(line int, err error) {
+	lineErr := &LineError{Line: line, Err: err}
+	im.result.NumFailed++
+	if len(im.result.Errors) < maxResultErrors {
+		im.result.Errors = append(im.result.Errors, lineErr)
+	}
+	if im.opts.OnError != nil {
+		im.opts.OnError(lineErr)
+	}
+}
+
+// flush adds the buffered documents to the index. When the batch is
+// rejected due invalid document, none of them is added so they are
+// added one by one for attributing the error to its line. It only
+// returns error on ErrMemoryLimit, since the next documents wouldn't
+// fit either.
+func (im *importer) flush() error {
+	defer func() {
+		im.docs = im.docs[:0]
+		im.lines = im.lines[:0]
+	}()
+	err := im.index.AddBatch(im.docs)
+	if err == nil {
+		im.result.NumAdded += len(im.docs)
+		return nil
+	}
+	// the documents before the failing one are already added
+	// on ErrMemoryLimit, so they must not be added again
+	var batchErr *knn.BatchError
+	if errors.As(err, &batchErr) && errors.Is(err, knn.ErrMemoryLimit) {
+		im.result.NumAdded += batchErr.Index
+		return fmt.Errorf("unable to add document at line %v due: %w", im.lines[batchErr.Index], batchErr.Err)
+	}
+	for i, doc := range im.docs {
+		err := im.index.Add(doc)
+		if errors.Is(err, knn.ErrMemoryLimit) {
+			return fmt.Errorf("unable to add document at line %v due: %w", im.lines[i], err)
+		}
+		if err != nil {
+			im.fail(im.lines[i], err)
+			continue
+		}
+		im.result.NumAdded++
+	}
+	return nil
+}
+
+// metadataOf returns metadata of `doc`, it is nil when `doc`
+// doesn't implement knn.MetadataDocument
+func metadataOf(doc knn.Document) map[string]interface{} {
+	if m, ok := doc.(knn.MetadataDocument); ok {
+		return m.GetMetadata()
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package knnio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/riandyrn/go-knn"
+)
+
+// ImportJSONL adds documents from JSON lines in `r` to `index`, each
+// line is JSON object holding the id, vector & optionally metadata of
+// single document (check Options for the field names). The documents
+// are added as knn.BasicDocument. The invalid lines (e.g malformed
+// JSON or vector with dimension other than Configs.VectorDimension)
+// are skipped & reported in the result. The lines are read one by one,
+// so only single batch of documents is held in memory.
+//
+// It only returns error when reading from `r` fails or the index
+// returns knn.ErrMemoryLimit, the documents before it are already
+// added.
+func ImportJSONL(index *knn.KNN, r io.Reader, opts Options) (ImportResult, error) {
+	opts = opts.withDefaults()
+	im := newImporter(index, opts)
+	br := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		b, err := br.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return im.result, fmt.Errorf("unable to read line %v due: %w", line, err)
+		}
+		if len(bytes.TrimSpace(b)) > 0 {
+			doc, parseErr := parseJSONLDocument(b, opts)
+			if parseErr != nil {
+				im.fail(line, parseErr)
+			} else if addErr := im.add(line, doc); addErr != nil {
+				return im.result, addErr
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	if err := im.flush(); err != nil {
+		return im.result, err
+	}
+	return im.result, nil
+}
+
+// parseJSONLDocument parses single line of JSONL into document
+func parseJSONLDocument(b []byte, opts Options) (*knn.BasicDocument, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, fmt.Errorf("%w: %v", knn.ErrInvalidDocument, err)
+	}
+	doc := &knn.BasicDocument{}
+	if err := decodeField(fields, opts.IDField, &doc.ID); err != nil {
+		return nil, err
+	}
+	if err := decodeField(fields, opts.VectorField, &doc.Vector); err != nil {
+		return nil, err
+	}
+	if raw, ok := fields[opts.MetadataField]; ok {
+		if err := json.Unmarshal(raw, &doc.Metadata); err != nil {
+			return nil, fmt.Errorf("%w: invalid field %q: %v", knn.ErrInvalidDocument, opts.MetadataField, err)
+		}
+	}
+	return doc, nil
+}
+
+// decodeField decodes the required field `name` into `v`
+func decodeField(fields map[string]json.RawMessage, name string, v interface{}) error {
+	raw, ok := fields[name]
+	if !ok {
+		return fmt.Errorf("%w: missing field %q", knn.ErrInvalidDocument, name)
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("%w: invalid field %q: %v", knn.ErrInvalidDocument, name, err)
+	}
+	return nil
+}
+
+// ExportJSONL writes the dense documents in `index` to `w` as JSON
+// lines which could be imported back by ImportJSONL using the same
+// `opts`. The expired documents are not exported. The documents are
+// written as they are visited by knn.KNN.Range, so the index is read
+// locked until all of them are written to `w`.
+func ExportJSONL(index *knn.KNN, w io.Writer, opts Options) error {
+	opts = opts.withDefaults()
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	var err error
+	index.Range(func(doc knn.Document) bool {
+		record := map[string]interface{}{
+			opts.IDField:     doc.GetID(),
+			opts.VectorField: doc.GetVector(),
+		}
+		if metadata := metadataOf(doc); len(metadata) > 0 {
+			record[opts.MetadataField] = metadata
+		}
+		if err = enc.Encode(record); err != nil {
+			err = fmt.Errorf("unable to export document %v due: %w", doc.GetID(), err)
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,304 @@
+// Package knnio (import path github.com/riandyrn/go-knn/io) reads &
+// writes documents of KNN index from & to common file formats.
+package knnio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strconv"
+
+	"github.com/riandyrn/go-knn"
+)
+
+// VecsFormat represents format of vectors file used by ANN benchmarks
+// (e.g SIFT1M). In all formats each vector is stored as its dimension
+// in little endian int32, followed by its values.
+type VecsFormat int
+
+const (
+	// Fvecs stores the values as little endian float32
+	Fvecs VecsFormat = iota
+	// Ivecs stores the values as little endian int32, it is usually
+	// used for ground truth which values are positions of the nearest
+	// neighbors in the base vectors file
+	Ivecs
+	// Bvecs stores the values as uint8
+	Bvecs
+)
+
+func (f VecsFormat) String() string {
+	switch f {
+	case Fvecs:
+		return "fvecs"
+	case Ivecs:
+		return "ivecs"
+	case Bvecs:
+		return "bvecs"
+	}
+	return "unknown"
+}
+
+// valueSize returns number of bytes of single value
+func (f VecsFormat) valueSize() int {
+	if f == Bvecs {
+		return 1
+	}
+	return 4
+}
+
+// VecsFormatOf returns format of vectors file at `path` based on
+// its extension, e.g ".fvecs" for Fvecs
+func VecsFormatOf(path string) (VecsFormat, error) {
+	ext := filepath.Ext(path)
+	for _, f := range []VecsFormat{Fvecs, Ivecs, Bvecs} {
+		if ext == "."+f.String() {
+			return f, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown vectors file extension: %v", ext)
+}
+
+// maxVecsDimension is the maximum accepted dimension, it protects
+// the reader from allocating huge buffer on corrupted file
+const maxVecsDimension = 1 << 20
+
+// VecsReader reads vectors from fvecs, ivecs or bvecs stream one by
+// one, so the whole file doesn't need to fit in memory.
+type VecsReader struct {
+	r      *bufio.Reader
+	format VecsFormat
+	buf    []byte
+	n      int
+}
+
+// NewVecsReader returns reader of vectors in `format` from `r`
+func NewVecsReader(r io.Reader, format VecsFormat) *VecsReader {
+	return &VecsReader{r: bufio.NewReader(r), format: format}
+}
+
+// Dimension returns dimension of the next vector without consuming
+// it, it returns io.EOF when there is no more vector
+func (r *VecsReader) Dimension() (int, error) {
+	header, err := r.r.Peek(4)
+	if err == io.EOF && len(header) == 0 {
+		return 0, io.EOF
+	}
+	if err != nil {
+		return 0, fmt.Errorf("unable to read dimension of vector %v due: %w", r.n, noEOF(err))
+	}
+	return int(int32(binary.LittleEndian.Uint32(header))), nil
+}
+
+// Read returns the next vector, it returns io.EOF when there is
+// no more vector
+func (r *VecsReader) Read() ([]float64, error) {
+	raw, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	size := r.format.valueSize()
+	vector := make([]float64, len(raw)/size)
+	for i := range vector {
+		switch r.format {
+		case Fvecs:
+			vector[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(raw[i*4:])))
+		case Ivecs:
+			vector[i] = float64(int32(binary.LittleEndian.Uint32(raw[i*4:])))
+		case Bvecs:
+			vector[i] = float64(raw[i])
+		}
+	}
+	return vector, nil
+}
+
+// ReadInts returns the next vector of Ivecs or Bvecs stream as
+// integers, it returns io.EOF when there is no more vector
+func (r *VecsReader) ReadInts() ([]int, error) {
+	if r.format == Fvecs {
+		return nil, fmt.Errorf("unable to read integers from %v", r.format)
+	}
+	raw, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	size := r.format.valueSize()
+	values := make([]int, len(raw)/size)
+	for i := range values {
+		if r.format == Bvecs {
+			values[i] = int(raw[i])
+			continue
+		}
+		values[i] = int(int32(binary.LittleEndian.Uint32(raw[i*4:])))
+	}
+	return values, nil
+}
+
+// next returns raw values of the next vector, the returned
+// slice is only valid until the next call
+func (r *VecsReader) next() ([]byte, error) {
+	dim, err := r.Dimension()
+	if err != nil {
+		return nil, err
+	}
+	if dim <= 0 || dim > maxVecsDimension {
+		return nil, fmt.Errorf("invalid dimension of vector %v: %v", r.n, dim)
+	}
+	r.r.Discard(4)
+	size := dim * r.format.valueSize()
+	if cap(r.buf) < size {
+		r.buf = make([]byte, size)
+	}
+	r.buf = r.buf[:size]
+	if _, err := io.ReadFull(r.r, r.buf); err != nil {
+		return nil, fmt.Errorf("unable to read vector %v due: %w", r.n, noEOF(err))
+	}
+	r.n++
+	return r.buf, nil
+}
+
+// noEOF converts io.EOF into io.ErrUnexpectedEOF, since the stream
+// ends in the middle of vector
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// VecsWriter writes vectors in fvecs, ivecs or bvecs format, call
+// Flush once all vectors are written.
+type VecsWriter struct {
+	w      *bufio.Writer
+	format VecsFormat
+	buf    []byte
+}
+
+// NewVecsWriter returns writer of vectors in `format` into `w`
+func NewVecsWriter(w io.Writer, format VecsFormat) *VecsWriter {
+	return &VecsWriter{w: bufio.NewWriter(w), format: format}
+}
+
+// Write writes single vector, for Ivecs & Bvecs the values must be
+// integers within the range of the format
+func (w *VecsWriter) Write(vector []float64) error {
+	if len(vector) == 0 {
+		return knn.ErrEmptyVector
+	}
+	raw := w.header(len(vector))
+	for i, v := range vector {
+		switch w.format {
+		case Fvecs:
+			binary.LittleEndian.PutUint32(raw[4+i*4:], math.Float32bits(float32(v)))
+		case Ivecs:
+			if v != math.Trunc(v) || v < math.MinInt32 || v > math.MaxInt32 {
+				return fmt.Errorf("value %v at %v is not int32", v, i)
+			}
+			binary.LittleEndian.PutUint32(raw[4+i*4:], uint32(int32(v)))
+		case Bvecs:
+			if v != math.Trunc(v) || v < 0 || v > math.MaxUint8 {
+				return fmt.Errorf("value %v at %v is not uint8", v, i)
+			}
+			raw[4+i] = byte(v)
+		}
+	}
+	_, err := w.w.Write(raw)
+	return err
+}
+
+// WriteInts writes single vector of integers, e.g the positions
+// of the nearest neighbors for ground truth in Ivecs
+func (w *VecsWriter) WriteInts(values []int) error {
+	vector := make([]float64, len(values))
+	for i, v := range values {
+		vector[i] = float64(v)
+	}
+	return w.Write(vector)
+}
+
+// Flush writes the buffered vectors to the underlying writer
+func (w *VecsWriter) Flush() error {
+	return w.w.Flush()
+}
+
+// header returns buffer for vector with dimension `dim` which
+// already holds the dimension
+func (w *VecsWriter) header(dim int) []byte {
+	size := 4 + dim*w.format.valueSize()
+	if cap(w.buf) < size {
+		w.buf = make([]byte, size)
+	}
+	w.buf = w.buf[:size]
+	binary.LittleEndian.PutUint32(w.buf, uint32(dim))
+	return w.buf
+}
+
+// VecsID returns id of document for vector at position `i` in
+// vectors file, it is the position itself since the ground truth
+// refers to the vectors by their position
+func VecsID(i int) string {
+	return strconv.Itoa(i)
+}
+
+// AddVecs reads all vectors from `r` & adds them to `index` using
+// AddBatch with maximum `batchSize` documents per batch. The documents
+// are knn.BasicDocument with id from VecsID. It returns the number of
+// added documents, which are kept even when error is returned.
+func AddVecs(index *knn.KNN, r *VecsReader, batchSize int) (int, error) {
+	if batchSize <= 0 {
+		return 0, fmt.Errorf("value of batch size must be greater than 0")
+	}
+	count := 0
+	batch := make([]knn.Document, 0, batchSize)
+	flush := func() error {
+		if err := index.AddBatch(batch); err != nil {
+			return fmt.Errorf("unable to add vectors %v-%v due: %w", count, count+len(batch)-1, err)
+		}
+		count += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+	for i := 0; ; i++ {
+		vector, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return count, err
+		}
+		batch = append(batch, &knn.BasicDocument{ID: VecsID(i), Vector: vector})
+		if len(batch) == batchSize {
+			if err := flush(); err != nil {
+				return count, err
+			}
+		}
+	}
+	if len(batch) > 0 {
+		if err := flush(); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// ReadGroundTruth reads all rows of ground truth in Ivecs format
+// from `r`, each row holds the positions of the nearest neighbors
+// of the query at the same position in the queries file
+func ReadGroundTruth(r io.Reader) ([][]int, error) {
+	vr := NewVecsReader(r, Ivecs)
+	var rows [][]int
+	for {
+		row, err := vr.ReadInts()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+}
@@ -0,0 +1,129 @@
+package knn
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Len returns number of documents in the index, including
+// the sparse documents & the expired documents which are not
+// swept yet. So it might be greater than the number of documents
+// visited by Range & RangeSparse or returned by IDs, since they
+// skip the expired documents.
+func (n *KNN) Len() int {
+	// acquire state for reading
+	state, release := n.readState()
+	// defer release
+	defer release()
+
+	return len(state.handles)
+}
+
+// Range calls `fn` for every dense document in the index until `fn`
+// returns false. The documents are not visited in any particular
+// order. The expired documents which are not swept yet are skipped.
+// The index is read locked during the iteration, so `fn` must not
+// write to the index, otherwise it would block forever.
+func (n *KNN) Range(fn func(doc Document) bool) {
+	n.rangeEntries(func(e entry) bool {
+		doc, ok := e.doc.(Document)
+		if !ok {
+			return true
+		}
+		return fn(doc)
+	})
+}
+
+// RangeSparse is like Range but for the sparse documents
+func (n *KNN) RangeSparse(fn func(doc SparseDocument) bool) {
+	n.rangeEntries(func(e entry) bool {
+		doc, ok := e.doc.(sparseDoc)
+		if !ok {
+			return true
+		}
+		return fn(doc.SparseDocument)
+	})
+}
+
+// rangeEntries calls `fn` for every entry which holds document
+// until `fn` returns false
+func (n *KNN) rangeEntries(fn func(e entry) bool) {
+	// acquire state for reading
+	state, release := n.readState()
+	// defer release
+	defer release()
+
+	now := time.Now().UnixNano()
+	for _, e := range state.entries {
+		if e.doc == nil || e.expired(now) {
+			continue
+		}
+		if !fn(e) {
+			return
+		}
+	}
+}
+
+// IDs returns maximum `limit` ids of documents in the index which
+// are greater than `cursor`, sorted in ascending order. Use empty
+// cursor to get the first page, then pass the last id of the page
+// as cursor to get the next one. The last page is reached when the
+// number of returned ids is less than `limit`. The expired documents
+// which are not swept yet are skipped.
+func (n *KNN) IDs(cursor string, limit int) ([]string, error) {
+	// check input validity
+	if limit <= 0 {
+		return nil, fmt.Errorf("value of limit must be greater than 0")
+	}
+	// acquire state for reading
+	state, release := n.readState()
+	// defer release
+	defer release()
+
+	// select the smallest ids after cursor
+	now := time.Now().UnixNano()
+	h := make(idHeap, 0)
+	for id, handle := range state.handles {
+		if id > cursor && !state.entries[handle].expired(now) {
+			h.pushLimit(id, limit)
+		}
+	}
+	return h.sorted(), nil
+}
+
+// idHeap is max-heap of ids, it is used for selecting the smallest
+// ids without sorting all of them.
+type idHeap []string
+
+func (h idHeap) Len() int            { return len(h) }
+func (h idHeap) Less(i, j int) bool  { return h[i] > h[j] }
+func (h idHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *idHeap) Push(x interface{}) { *h = append(*h, x.(string)) }
+func (h *idHeap) Pop() interface{} {
+	old := *h
+	id := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return id
+}
+
+// pushLimit adds `id` into the heap which holds maximum `limit`
+// smallest ids, the largest id is dropped when the heap is full.
+func (h *idHeap) pushLimit(id string, limit int) {
+	if h.Len() < limit {
+		heap.Push(h, id)
+		return
+	}
+	if id < (*h)[0] {
+		(*h)[0] = id
+		heap.Fix(h, 0)
+	}
+}
+
+// sorted returns the ids in the heap in ascending order
+func (h idHeap) sorted() []string {
+	ids := []string(h)
+	sort.Strings(ids)
+	return ids
+}
@@ -1,34 +1,70 @@
 package knn
 
 import (
+	"container/heap"
 	"fmt"
 	"math"
 	"sort"
 	"sync"
-
-	"github.com/riandyrn/lsh"
+	"sync/atomic"
+	"time"
 )
 
 // KNN is the index for searching nearest neighbors.
 // It is based on LSH index.
 type KNN struct {
-	// LSH index which will be used for indexing documents
-	lsh *lsh.BasicLsh
+	// state holds the LSH index & the documents, all writes
+	// are applied to it.
+	state *indexState
 
 	// We also store value of vector dimension because we
 	// still need it for input validation
 	vectorDimension int
 
-	// We use another map because LSH index only stores document
-	// id, so to get full information of document we need another
-	// map for it.
-	//
-	// The behavior of LSH index is pretty reasonable, because in
-	// the index, the document would be stored in all hash tables
-	// (duplicated). So it's best to just store the necessary info
-	// on the LSH index to minimize memory usage, which in this
-	// case is document id.
-	docMap sync.Map
+	// When quantization is enabled, quantizer is used for encoding
+	// document vectors into codes stored in the entries. The codes are
+	// used for ranking LSH candidates before top `rescoreSize` of
+	// them are rescored using exact vectors.
+	quantizer   *ScalarQuantizer
+	rescoreSize int
+
+	// memoryLimit is the maximum estimated memory usage in
+	// bytes, the value of 0 means no limit
+	memoryLimit int64
+
+	// When capacity is greater than 0, the index holds at most
+	// `capacity` documents, the victim of the eviction is chosen
+	// by the evictor of the state. numEvictions is updated
+	// atomically.
+	capacity     int
+	onEvict      func(docID string)
+	numEvictions uint64
+
+	// In snapshot isolation mode, reads are served from immutable
+	// copy of state which is published on Commit(). The snapshot is
+	// swapped atomically, so reads never need to acquire the lock.
+	// snapshotBytes holds estimated memory used by the snapshot.
+	snapshotIsolation bool
+	snapshot          atomic.Value
+	snapshotBytes     int64
+	dirty             bool
+
+	// seq is the sequence number of the last mutation, the events
+	// of mutations are kept in pending until the lock is released
+	// (check unlock()). hooks & subs are guarded by the write lock.
+	// dispatched is closed once the events of the last write are
+	// dispatched, so the next write could dispatch its events in
+	// order without holding any lock.
+	seq          uint64
+	pending      []ChangeEvent
+	hooks        []Hook
+	subs         []*Subscription
+	numListeners int32
+	dispatched   chan struct{}
+
+	// closeCh is used for stopping background goroutines
+	closeCh   chan struct{}
+	closeOnce sync.Once
 
 	// We use mutex because the LSH implementation used
 	// normal map instead of sync map, yet we are expecting
@@ -38,43 +74,211 @@ type KNN struct {
 }
 
 // NewKNN returns new initialized instance of KNN
-// index. It uses basic LSH algorithm implemented on
+// index. It uses basic LSH algorithm ported from
 // `github.com/ekzhu/lsh` as its engine to search for
-// nearest neighbors.
+// nearest neighbors. The configs are not validated,
+// use Configs.Validate() for checking them first.
 func NewKNN(configs Configs) *KNN {
-	return &KNN{
-		vectorDimension: configs.VectorDimension,
-		lsh: lsh.NewBasicLsh(
-			configs.VectorDimension,
-			configs.NumHashTable,
-			configs.NumHyperplane,
-			float64(configs.SlotSize),
-		),
-		docMap: sync.Map{},
+	n := &KNN{
+		vectorDimension:   configs.VectorDimension,
+		quantizer:         configs.Quantizer,
+		rescoreSize:       configs.RescoreSize,
+		memoryLimit:       configs.MemoryLimit,
+		capacity:          configs.Capacity,
+		onEvict:           configs.OnEvict,
+		snapshotIsolation: configs.SnapshotIsolation,
+		closeCh:           make(chan struct{}),
 	}
+	n.state = newIndexState(nil)
+	n.state.lsh = newLshFromConfigs(configs, n.state.pointOf)
+	if n.capacity > 0 {
+		n.state.evictor = newEvictor(configs.EvictionPolicy)
+	}
+	if n.snapshotIsolation {
+		n.publish()
+		if configs.PublishInterval > 0 {
+			go n.publishPeriodically(configs.PublishInterval)
+		}
+	}
+	if configs.SweepInterval > 0 {
+		go n.sweepPeriodically(configs.SweepInterval)
+	}
+	return n
 }
 
-// Add is used for introduce new document to index
+// newLshFromConfigs returns LSH index with params from `configs`,
+// pointOf is used for splitting buckets when MaxBucketSize is set.
+func newLshFromConfigs(configs Configs, pointOf func(h uint32) point) *basicLsh {
+	lsh := newBasicLsh(
+		configs.VectorDimension,
+		configs.NumHashTable,
+		configs.NumHyperplane,
+		float64(configs.SlotSize),
+		configs.SparseProjection,
+		configs.NumWorkers,
+	)
+	if configs.MaxBucketSize > 0 {
+		numSplitHyperplane := configs.NumSplitHyperplane
+		if numSplitHyperplane <= 0 {
+			numSplitHyperplane = 2
+		}
+		lsh.enableSplit(configs.MaxBucketSize, numSplitHyperplane, pointOf)
+	}
+	return lsh
+}
+
+// Add is used for introduce new document to index. If document
+// with the same id already exists, it will be replaced. It returns
+// ErrMemoryLimit when the index is already full. When the document
+// implements Expirer, it is expired at the returned time.
 func (n *KNN) Add(doc Document) error {
+	// check input validity
+	if doc == nil {
+		return fmt.Errorf("%w: trying to insert bad document", ErrInvalidDocument)
+	}
+	return n.add(doc, expiryOf(doc))
+}
+
+// AddWithTTL is like Add but the document is expired after `ttl`.
+// The expired document is no longer returned by the index, it is
+// removed from the index on the next sweep (check SweepInterval).
+func (n *KNN) AddWithTTL(doc Document, ttl time.Duration) error {
+	// check input validity
+	if ttl <= 0 {
+		return fmt.Errorf("value of ttl must be greater than 0")
+	}
+	return n.add(doc, time.Now().Add(ttl).UnixNano())
+}
+
+// AddBatch adds `docs` to index just like calling Add for each of
+// them. All documents are validated before any of them is added, so
+// single invalid document fails the whole batch. The returned error
+// is *BatchError, notice that when it wraps ErrMemoryLimit, the
+// documents before the failing one are already added.
+func (n *KNN) AddBatch(docs []Document) error {
+	entries := make([]entry, 0, len(docs))
+	for i, doc := range docs {
+		e, err := n.newEntry(doc, expiryOf(doc))
+		if err != nil {
+			return &BatchError{Index: i, Err: err}
+		}
+		entries = append(entries, e)
+	}
+	for i, e := range entries {
+		if err := n.insert(docs[i].GetID(), e); err != nil {
+			return &BatchError{Index: i, Err: err}
+		}
+	}
+	return nil
+}
+
+// add inserts `doc` which expires at `expiresAt` to index
+func (n *KNN) add(doc Document, expiresAt int64) error {
+	e, err := n.newEntry(doc, expiresAt)
+	if err != nil {
+		return err
+	}
+	return n.insert(doc.GetID(), e)
+}
+
+// newEntry validates `doc` then returns entry holding it
+func (n *KNN) newEntry(doc Document, expiresAt int64) (entry, error) {
 	// check input validity
 	if doc == nil || len(doc.GetID()) == 0 || len(doc.GetVector()) == 0 {
-		return fmt.Errorf("trying to insert bad document")
+		return entry{}, fmt.Errorf("%w: trying to insert bad document", ErrInvalidDocument)
 	}
 	dim := len(doc.GetVector())
 	if dim != n.vectorDimension {
-		return fmt.Errorf("unexpected vector dimension, expected: %v, got: %v", n.vectorDimension, dim)
+		return entry{}, &DimensionError{Expected: n.vectorDimension, Got: dim}
 	}
-	// acquire lock
-	n.mux.Lock()
-	// defer unlock
-	defer n.mux.Unlock()
+	e := entry{doc: doc, expiresAt: expiresAt}
+	// store quantized vector if enabled, without rescoring
+	// the exact vector is no longer needed so only the codes
+	// are kept
+	if n.quantizer != nil {
+		e.codes = n.quantizer.Encode(doc.GetVector())
+		if n.rescoreSize == 0 {
+			e.doc = newQuantizedDoc(doc, e.codes, n.quantizer)
+		}
+	}
+	return e, nil
+}
 
-	// insert document to LSH index
-	n.lsh.Insert(doc.GetVector(), doc.GetID())
-	// insert document to map
-	n.docMap.Store(doc.GetID(), doc)
+// insert adds document with `id` in entry `e` to index, the
+// existing document with the same id is replaced. When the index
+// is at its capacity, the documents are evicted to make a room.
+func (n *KNN) insert(id string, e entry) error {
+	var evicted []string
+	err := func() error {
+		// acquire lock
+		n.mux.Lock()
+		// defer unlock
+		defer n.unlock()
 
-	return nil
+		// the hash values are calculated under lock since the
+		// LSH might be swapped by Reconfigure, their keys are
+		// kept in the entry
+		hvs := hashOf(n.state.lsh, e)
+		e.keys = hvs.keys
+		// make sure the document fits in the memory
+		if err := n.checkMemoryLimit(id, e); err != nil {
+			return err
+		}
+		evicted = n.evict(id)
+		op := OpAdd
+		if _, ok := n.state.handles[id]; ok {
+			op = OpUpsert
+		}
+		// insert document to index
+		n.state.insert(id, e, hvs)
+		n.dirty = true
+		n.record(op, id, e)
+
+		return nil
+	}()
+	// call the callback outside of the lock, so it
+	// could access the index
+	if n.onEvict != nil {
+		for _, docID := range evicted {
+			n.onEvict(docID)
+		}
+	}
+	return err
+}
+
+// evict deletes documents chosen by the evictor until there is a
+// room for document with `id`, it returns ids of the evicted
+// documents. The caller must hold the lock.
+func (n *KNN) evict(id string) []string {
+	if n.capacity <= 0 {
+		return nil
+	}
+	// replacing document doesn't need a room
+	if _, ok := n.state.handles[id]; ok {
+		return nil
+	}
+	// remove the expired documents first, so the documents
+	// which are still alive are not evicted needlessly, the
+	// sweeping is skipped when nothing could have expired yet
+	if len(n.state.handles) >= n.capacity && n.state.nextExpiry > 0 {
+		if now := time.Now().UnixNano(); n.state.nextExpiry <= now {
+			n.deleteExpired(now)
+		}
+	}
+	var evicted []string
+	for len(n.state.handles) >= n.capacity {
+		h, ok := n.state.evictor.victim()
+		if !ok {
+			break
+		}
+		e := n.state.entries[h]
+		docID := e.doc.(interface{ GetID() string }).GetID()
+		n.state.delete(docID)
+		n.record(OpEvict, docID, e)
+		atomic.AddUint64(&n.numEvictions, 1)
+		evicted = append(evicted, docID)
+	}
+	return evicted
 }
 
 // Query returns maximum `k` similar documents. The result
@@ -82,87 +286,470 @@ func (n *KNN) Add(doc Document) error {
 func (n *KNN) Query(vector []float64, k int) ([]ResultDocument, error) {
 	// check input validity
 	if len(vector) == 0 {
-		return nil, fmt.Errorf("vector must not empty")
+		return nil, ErrEmptyVector
+	}
+	if len(vector) != n.vectorDimension {
+		return nil, &DimensionError{Expected: n.vectorDimension, Got: len(vector)}
 	}
 	if k <= 0 {
-		return nil, fmt.Errorf("value of k must be greater than 0")
+		return nil, ErrInvalidK
 	}
-	// acquire read lock
-	n.mux.RLock()
-	// defer read unlock
-	defer n.mux.RUnlock()
-
-	// get ids of similar documents
-	ids := n.lsh.Query(vector)
-	// get full document info from docMap including
-	// distance from input vector
-	resultDocs := make([]ResultDocument, 0, len(ids))
-	for _, id := range ids {
-		v, ok := n.docMap.Load(id)
-		if !ok {
+	// acquire state for reading
+	state, release := n.readState()
+	// defer release
+	defer release()
+
+	// get handles of similar documents
+	handles := state.query(state.lsh.hash(vector))
+	// calculate squared distance of the documents from input vector
+	exact := exactDistance(vector)
+	if n.quantizer == nil {
+		return n.rankCandidates(state, handles, k, exact, nil), nil
+	}
+	approx := func(e entry) float64 {
+		if e.codes == nil {
+			return exact(e)
+		}
+		return n.quantizer.squaredDistance(e.codes, vector)
+	}
+	if n.rescoreSize == 0 {
+		return n.rankCandidates(state, handles, k, approx, nil), nil
+	}
+	return n.rankCandidates(state, handles, k, approx, exact), nil
+}
+
+// QueryByID returns maximum `k` documents similar to the document
+// with `docID`, the document itself is excluded from the result. It
+// returns ErrNotFound when the document is not in the index.
+func (n *KNN) QueryByID(docID string, k int) ([]ResultDocument, error) {
+	// check input validity
+	if len(docID) == 0 {
+		return nil, fmt.Errorf("%w: document id must not empty", ErrInvalidDocument)
+	}
+	if k <= 0 {
+		return nil, ErrInvalidK
+	}
+	// load document from index
+	state, release := n.readState()
+	e, ok := state.load(docID)
+	release()
+	if !ok || e.expired(time.Now().UnixNano()) {
+		return nil, ErrNotFound
+	}
+	// query one more document since the document itself
+	// is likely in the result
+	var resultDocs []ResultDocument
+	var err error
+	if doc, ok := e.doc.(sparseDoc); ok {
+		indices, values := doc.GetSparseVector()
+		resultDocs, err = n.QuerySparse(indices, values, k+1)
+	} else {
+		resultDocs, err = n.Query(e.doc.(Document).GetVector(), k+1)
+	}
+	if err != nil {
+		return nil, err
+	}
+	filtered := resultDocs[:0]
+	for _, resultDoc := range resultDocs {
+		if resultDoc.Document != nil && resultDoc.Document.GetID() == docID {
+			continue
+		}
+		if resultDoc.SparseDocument != nil && resultDoc.SparseDocument.GetID() == docID {
 			continue
 		}
-		doc := v.(Document)
-		distance := calcDistance(doc.GetVector(), vector)
-		resultDocs = append(resultDocs, ResultDocument{
-			Document: doc,
-			Distance: distance,
-		})
+		filtered = append(filtered, resultDoc)
 	}
-	// sort by distance from minimum to maximum
-	sort.Slice(resultDocs, func(i int, j int) bool {
-		return resultDocs[i].Distance < resultDocs[j].Distance
-	})
-	// cut the result into max k documents
-	if len(resultDocs) > k {
-		resultDocs = resultDocs[:k]
+	if len(filtered) > k {
+		filtered = filtered[:k]
+	}
+	return filtered, nil
+}
+
+// QueryRadius returns all documents which distance from `vector` is
+// not greater than `radius`, sorted from most similar to least similar
+// documents. Just like Query, only the documents sharing bucket with
+// `vector` are considered. The distance is calculated from the vectors
+// held by the documents, so it is only approximation when Quantizer is
+// set without RescoreSize.
+func (n *KNN) QueryRadius(vector []float64, radius float64) ([]ResultDocument, error) {
+	// check input validity
+	if len(vector) == 0 {
+		return nil, ErrEmptyVector
+	}
+	if len(vector) != n.vectorDimension {
+		return nil, &DimensionError{Expected: n.vectorDimension, Got: len(vector)}
+	}
+	if radius < 0 {
+		return nil, fmt.Errorf("value of radius must not negative")
+	}
+	// acquire state for reading
+	state, release := n.readState()
+	// defer release
+	defer release()
+
+	// collect documents within radius, compare the squared
+	// distance so we don't need to take square root
+	exact := exactDistance(vector)
+	maxDistance := radius * radius
+	candidates := make([]candidate, 0)
+	for _, h := range state.query(state.lsh.hash(vector)) {
+		distance := exact(state.entries[h])
+		if distance <= maxDistance {
+			candidates = append(candidates, candidate{handle: h, distance: distance})
+		}
 	}
-	return resultDocs, nil
+	sortCandidates(candidates)
+	return resultDocsOf(state, candidates), nil
 }
 
-// calcDistance is used for calculating vector distance using
-// euclidean formula. Input `v1` & `v2` assummed has same dimension.
-func calcDistance(v1, v2 []float64) float64 {
+// exactDistance returns function for calculating squared distance
+// of document in entry from `vector`
+func exactDistance(vector []float64) func(e entry) float64 {
+	return func(e entry) float64 {
+		if doc, ok := e.doc.(sparseDoc); ok {
+			indices, values := doc.GetSparseVector()
+			return calcSparseDenseSquaredDistance(indices, values, vector)
+		}
+		return calcSquaredDistance(e.doc.(Document).GetVector(), vector)
+	}
+}
+
+// candidate is document in the LSH query result
+type candidate struct {
+	handle   uint32
+	distance float64
+}
+
+// rankCandidates calculates the squared distance of documents in
+// `handles` using `distance` & returns top `k` of them. When `rescore`
+// is not nil, the squared distance of top candidates is recalculated
+// using it before the final top `k` is taken.
+func (n *KNN) rankCandidates(state *indexState, handles []uint32, k int, distance, rescore func(e entry) float64) []ResultDocument {
+	limit := k
+	if rescore != nil && n.rescoreSize > k {
+		limit = n.rescoreSize
+	}
+	candidates := selectCandidates(state, handles, limit, distance)
+	// rescore top candidates
+	if rescore != nil {
+		for i := range candidates {
+			candidates[i].distance = rescore(state.entries[candidates[i].handle])
+		}
+		sortCandidates(candidates)
+		// cut the result into max k documents
+		if len(candidates) > k {
+			candidates = candidates[:k]
+		}
+	}
+	return resultDocsOf(state, candidates)
+}
+
+// resultDocsOf returns full document info of `candidates`, the
+// documents are marked as used for the eviction
+func resultDocsOf(state *indexState, candidates []candidate) []ResultDocument {
+	resultDocs := make([]ResultDocument, 0, len(candidates))
+	for _, c := range candidates {
+		resultDoc := ResultDocument{Distance: math.Sqrt(c.distance)}
+		switch doc := state.entries[c.handle].doc.(type) {
+		case sparseDoc:
+			state.evictor.touch(c.handle, doc.GetID())
+			resultDoc.SparseDocument = doc.SparseDocument
+		case Document:
+			state.evictor.touch(c.handle, doc.GetID())
+			resultDoc.Document = doc
+		}
+		resultDocs = append(resultDocs, resultDoc)
+	}
+	return resultDocs
+}
+
+// selectCandidates returns maximum `limit` documents in `handles`
+// which have the lowest `distance`, sorted from minimum to maximum
+// distance. It keeps the best candidates in bounded max-heap, so
+// the candidates don't need to be sorted entirely.
+func selectCandidates(state *indexState, handles []uint32, limit int, distance func(e entry) float64) []candidate {
+	if limit > len(handles) {
+		limit = len(handles)
+	}
+	h := make(candidateHeap, 0, limit)
+	for _, handle := range handles {
+		c := candidate{
+			handle:   handle,
+			distance: distance(state.entries[handle]),
+		}
+		if len(h) < limit {
+			h = append(h, c)
+			if len(h) == limit {
+				heap.Init(&h)
+			}
+			continue
+		}
+		// replace the worst candidate in the heap
+		if c.distance < h[0].distance {
+			h[0] = c
+			heap.Fix(&h, 0)
+		}
+	}
+	candidates := []candidate(h)
+	sortCandidates(candidates)
+	return candidates
+}
+
+// sortCandidates sorts `candidates` by distance from minimum
+// to maximum
+func sortCandidates(candidates []candidate) {
+	sort.Slice(candidates, func(i int, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+}
+
+// candidateHeap is max-heap of candidates ordered by distance
+type candidateHeap []candidate
+
+func (h candidateHeap) Len() int { return len(h) }
+
+func (h candidateHeap) Less(i, j int) bool { return h[i].distance > h[j].distance }
+
+func (h candidateHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *candidateHeap) Push(x interface{}) { *h = append(*h, x.(candidate)) }
+
+func (h *candidateHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// calcSquaredDistance is used for calculating squared vector distance
+// using euclidean formula. The square root is only taken for the final
+// result, since comparing squared distances gives the same order.
+// Input `v1` & `v2` assummed has same dimension.
+func calcSquaredDistance(v1, v2 []float64) float64 {
 	sum := 0.0
 	for i := 0; i < len(v1); i++ {
-		sum += math.Pow(float64(v2[i]-v1[i]), 2.0)
+		d := v2[i] - v1[i]
+		sum += d * d
 	}
-	distance := math.Sqrt(sum)
-
-	return distance
+	return sum
 }
 
 // Delete is used to delete appointed document from index.
-// The document will literally deleted from memory.
+// The document will literally deleted from memory. It returns
+// ErrNotFound when the document is not in the index.
 func (n *KNN) Delete(docID string) error {
 	// check input validity
 	if len(docID) == 0 {
-		return fmt.Errorf("document id must not empty")
+		return fmt.Errorf("%w: document id must not empty", ErrInvalidDocument)
 	}
 	// acquire lock
 	n.mux.Lock()
 	// defer unlock
-	defer n.mux.Unlock()
+	defer n.unlock()
 
-	// delete from lsh index
-	n.lsh.Delete(docID)
-	// delete document from map
-	n.docMap.Delete(docID)
+	// delete document from index
+	e, ok := n.state.load(docID)
+	if !ok {
+		return ErrNotFound
+	}
+	n.state.delete(docID)
+	n.dirty = true
+	n.record(OpDelete, docID, e)
 
 	return nil
 }
 
-// Get is used to get single document from index.
-// If document not found returns nil instead.
+// DeleteWhere deletes all dense documents which `match` returns
+// true, it returns the number of deleted documents. Unlike calling
+// Delete in a loop, the hash tables are scanned only once & the
+// lock is acquired only once. Since the index is locked during the
+// deletion, `match` must not access the index.
+func (n *KNN) DeleteWhere(match func(doc Document) bool) (int, error) {
+	// check input validity
+	if match == nil {
+		return 0, fmt.Errorf("match function must not nil")
+	}
+	// acquire lock
+	n.mux.Lock()
+	// defer unlock
+	defer n.unlock()
+
+	// delete matching documents from index
+	count := n.state.deleteWhere(func(id string, e entry) bool {
+		doc, ok := e.doc.(Document)
+		if !ok || !match(doc) {
+			return false
+		}
+		n.record(OpDelete, id, e)
+		return true
+	})
+	if count > 0 {
+		n.dirty = true
+	}
+
+	return count, nil
+}
+
+// Reset deletes all documents from index. It is much cheaper than
+// deleting the documents one by one since the hash tables are just
+// dropped.
+func (n *KNN) Reset() {
+	// acquire lock
+	n.mux.Lock()
+	// defer unlock
+	defer n.unlock()
+
+	n.state.reset()
+	n.dirty = true
+	n.record(OpReset, "", entry{})
+}
+
+// Get is used to get single document from index. If document
+// not found or it is a sparse document returns ErrNotFound.
 func (n *KNN) Get(docID string) (Document, error) {
 	// check input validity
 	if len(docID) == 0 {
-		return nil, fmt.Errorf("document id must not empty")
+		return nil, fmt.Errorf("%w: document id must not empty", ErrInvalidDocument)
 	}
-	// load document from doc map
-	v, ok := n.docMap.Load(docID)
+	// acquire state for reading
+	state, release := n.readState()
+	// defer release
+	defer release()
+
+	// load document from index
+	e, ok := state.load(docID)
+	if !ok || e.expired(time.Now().UnixNano()) {
+		return nil, ErrNotFound
+	}
+	doc, ok := e.doc.(Document)
 	if !ok {
-		return nil, nil
+		return nil, ErrNotFound
+	}
+	state.evictor.touch(state.handles[docID], docID)
+	return doc, nil
+}
+
+// Commit publishes all writes since the last commit to readers.
+// It is only necessary in snapshot isolation mode, otherwise the
+// writes are visible immediately & Commit does nothing. The snapshot
+// is copy of the hash tables & the document handles, so Commit
+// holds the write lock for time proportional to the index size.
+func (n *KNN) Commit() {
+	if !n.snapshotIsolation {
+		return
+	}
+	// acquire lock, so the snapshot never contains
+	// partially applied write
+	n.mux.Lock()
+	// defer unlock
+	defer n.mux.Unlock()
+
+	if !n.dirty {
+		return
+	}
+	n.publish()
+	n.dirty = false
+}
+
+// publish replaces the snapshot with copy of the current state.
+// The caller must hold the lock.
+func (n *KNN) publish() {
+	snapshot := n.state.clone()
+	n.snapshot.Store(snapshot)
+	n.snapshotBytes = snapshot.cloneBytes()
+}
+
+// Close stops background goroutines of the index. The index
+// could still be used after it is closed, but the writes will
+// no longer be published automatically, the expired documents
+// will no longer be swept & the hash tables will be updated
+// sequentially.
+func (n *KNN) Close() {
+	n.closeOnce.Do(func() {
+		close(n.closeCh)
+		// acquire lock, so no write is using the pool
+		n.mux.Lock()
+		defer n.mux.Unlock()
+		n.state.lsh.pool.close()
+		n.state.lsh.pool = nil
+	})
+}
+
+// publishPeriodically calls Commit on every `interval`
+// until the index is closed
+func (n *KNN) publishPeriodically(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			n.Commit()
+		case <-n.closeCh:
+			return
+		}
+	}
+}
+
+// DeleteExpired deletes all expired documents from index, it
+// returns the number of deleted documents. It is called on every
+// SweepInterval when it is set.
+func (n *KNN) DeleteExpired() int {
+	now := time.Now().UnixNano()
+	// acquire lock
+	n.mux.Lock()
+	// defer unlock
+	defer n.unlock()
+
+	return n.deleteExpired(now)
+}
+
+// deleteExpired deletes the documents which are expired at `now`,
+// it returns the number of deleted documents. The caller must hold
+// the lock.
+func (n *KNN) deleteExpired(now int64) int {
+	// the earliest expiry of the remaining documents is
+	// collected along the way
+	var nextExpiry int64
+	count := n.state.deleteWhere(func(id string, e entry) bool {
+		if !e.expired(now) {
+			if e.expiresAt > 0 && (nextExpiry == 0 || e.expiresAt < nextExpiry) {
+				nextExpiry = e.expiresAt
+			}
+			return false
+		}
+		n.record(OpExpire, id, e)
+		return true
+	})
+	n.state.nextExpiry = nextExpiry
+	if count > 0 {
+		n.dirty = true
 	}
-	return v.(Document), nil
+	return count
+}
+
+// sweepPeriodically calls DeleteExpired on every `interval`
+// until the index is closed
+func (n *KNN) sweepPeriodically(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			n.DeleteExpired()
+		case <-n.closeCh:
+			return
+		}
+	}
+}
+
+// readState returns the state which should be used for serving
+// reads, along with function which must be called once the reading
+// is done. In snapshot isolation mode the latest published snapshot
+// is returned without acquiring any lock.
+func (n *KNN) readState() (*indexState, func()) {
+	if n.snapshotIsolation {
+		return n.snapshot.Load().(*indexState), func() {}
+	}
+	n.mux.RLock()
+	return n.state, n.mux.RUnlock
 }
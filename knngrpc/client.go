@@ -0,0 +1,153 @@
+package knngrpc
+
+import (
+	"context"
+	"io"
+
+	"github.com/riandyrn/go-knn"
+	"github.com/riandyrn/go-knn/knngrpc/knnpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Client is wrapper of knnpb.KNNClient which accepts & returns the
+// types of knn package. The documents returned by the client are
+// knn.BasicDocument. When the document is not found, the client
+// returns knn.ErrNotFound, the other errors are status errors.
+type Client struct {
+	client knnpb.KNNClient
+}
+
+// NewClient returns client which calls the service through `conn`
+func NewClient(conn grpc.ClientConnInterface) *Client {
+	return &Client{client: knnpb.NewKNNClient(conn)}
+}
+
+// Add adds single document to the index
+func (c *Client) Add(ctx context.Context, doc knn.Document) error {
+	pbDoc, err := toPBDocument(doc)
+	if err != nil {
+		return err
+	}
+	_, err = c.client.Add(ctx, &knnpb.AddRequest{Document: pbDoc})
+	return fromStatus(err)
+}
+
+// AddStream sends documents received from `docs` to the index in
+// single stream until `docs` is closed. The invalid documents don't
+// abort the stream, they are reported in the response instead. When
+// the stream fails, it returns without draining `docs`, so the sender
+// should also stop on `ctx` cancellation.
+func (c *Client) AddStream(ctx context.Context, docs <-chan knn.Document) (*knnpb.AddStreamResponse, error) {
+	// cancel the stream when we are returning early, so the
+	// server doesn't treat the partial stream as complete
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := c.client.AddStream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for doc := range docs {
+		pbDoc, err := toPBDocument(doc)
+		if err != nil {
+			return nil, err
+		}
+		if err := stream.Send(&knnpb.AddRequest{Document: pbDoc}); err != nil {
+			// the actual error is returned by CloseAndRecv
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+	}
+	resp, err := stream.CloseAndRecv()
+	return resp, fromStatus(err)
+}
+
+// Delete deletes single document from the index
+func (c *Client) Delete(ctx context.Context, docID string) error {
+	_, err := c.client.Delete(ctx, &knnpb.DeleteRequest{Id: docID})
+	return fromStatus(err)
+}
+
+// Get returns single document from the index
+func (c *Client) Get(ctx context.Context, docID string) (knn.Document, error) {
+	doc, err := c.client.Get(ctx, &knnpb.GetRequest{Id: docID})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+	return fromPBDocument(doc), nil
+}
+
+// Query returns maximum `k` documents nearest to `vector`
+func (c *Client) Query(ctx context.Context, vector []float64, k int) ([]knn.ResultDocument, error) {
+	stream, err := c.client.Query(ctx, &knnpb.QueryRequest{Vector: vector, K: int32(k)})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+	return recvResultDocs(stream)
+}
+
+// QueryByID returns maximum `k` documents nearest to the document
+// with `docID`, the document itself is excluded
+func (c *Client) QueryByID(ctx context.Context, docID string, k int) ([]knn.ResultDocument, error) {
+	stream, err := c.client.QueryByID(ctx, &knnpb.QueryByIDRequest{Id: docID, K: int32(k)})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+	return recvResultDocs(stream)
+}
+
+// Stats returns statistics of the index
+func (c *Client) Stats(ctx context.Context) (knn.Stats, error) {
+	resp, err := c.client.Stats(ctx, &knnpb.StatsRequest{})
+	if err != nil {
+		return knn.Stats{}, fromStatus(err)
+	}
+	stats := knn.Stats{
+		NumDocuments:   int(resp.GetNumDocuments()),
+		HashTableBytes: resp.GetHashTableBytes(),
+		BucketBytes:    resp.GetBucketBytes(),
+		DocumentBytes:  resp.GetDocumentBytes(),
+		VectorBytes:    resp.GetVectorBytes(),
+		TotalBytes:     resp.GetTotalBytes(),
+		NumEvictions:   resp.GetNumEvictions(),
+	}
+	for _, table := range resp.GetTables() {
+		stats.Tables = append(stats.Tables, knn.TableStats{
+			NumBuckets:        int(table.GetNumBuckets()),
+			LargestBucketSize: int(table.GetLargestBucketSize()),
+			NumSplitBuckets:   int(table.GetNumSplitBuckets()),
+		})
+	}
+	return stats, nil
+}
+
+// recvResultDocs receives the result documents from `stream`
+// until the end of the stream
+func recvResultDocs(stream grpc.ServerStreamingClient[knnpb.ResultDocument]) ([]knn.ResultDocument, error) {
+	resultDocs := make([]knn.ResultDocument, 0)
+	for {
+		resultDoc, err := stream.Recv()
+		if err == io.EOF {
+			return resultDocs, nil
+		}
+		if err != nil {
+			return nil, fromStatus(err)
+		}
+		resultDocs = append(resultDocs, knn.ResultDocument{
+			Document: fromPBDocument(resultDoc.GetDocument()),
+			Distance: resultDoc.GetDistance(),
+		})
+	}
+}
+
+// fromStatus converts NotFound status error into knn.ErrNotFound
+func fromStatus(err error) error {
+	if status.Code(err) == codes.NotFound {
+		return knn.ErrNotFound
+	}
+	return err
+}
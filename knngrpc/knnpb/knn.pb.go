@@ -0,0 +1,897 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: knn.proto
+
+package knnpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Document struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Vector        []float64              `protobuf:"fixed64,2,rep,packed,name=vector,proto3" json:"vector,omitempty"`
+	Metadata      *structpb.Struct       `protobuf:"bytes,3,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Document) Reset() {
+	*x = Document{}
+	mi := &file_knn_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Document) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Document) ProtoMessage() {}
+
+func (x *Document) ProtoReflect() protoreflect.Message {
+	mi := &file_knn_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Document.ProtoReflect.Descriptor instead.
+func (*Document) Descriptor() ([]byte, []int) {
+	return file_knn_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Document) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Document) GetVector() []float64 {
+	if x != nil {
+		return x.Vector
+	}
+	return nil
+}
+
+func (x *Document) GetMetadata() *structpb.Struct {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type ResultDocument struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Document *Document              `protobuf:"bytes,1,opt,name=document,proto3" json:"document,omitempty"`
+	// distance is the euclidean distance from the query, the lower
+	// the better
+	Distance      float64 `protobuf:"fixed64,2,opt,name=distance,proto3" json:"distance,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResultDocument) Reset() {
+	*x = ResultDocument{}
+	mi := &file_knn_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResultDocument) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResultDocument) ProtoMessage() {}
+
+func (x *ResultDocument) ProtoReflect() protoreflect.Message {
+	mi := &file_knn_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResultDocument.ProtoReflect.Descriptor instead.
+func (*ResultDocument) Descriptor() ([]byte, []int) {
+	return file_knn_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ResultDocument) GetDocument() *Document {
+	if x != nil {
+		return x.Document
+	}
+	return nil
+}
+
+func (x *ResultDocument) GetDistance() float64 {
+	if x != nil {
+		return x.Distance
+	}
+	return 0
+}
+
+type AddRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Document      *Document              `protobuf:"bytes,1,opt,name=document,proto3" json:"document,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddRequest) Reset() {
+	*x = AddRequest{}
+	mi := &file_knn_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddRequest) ProtoMessage() {}
+
+func (x *AddRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_knn_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddRequest.ProtoReflect.Descriptor instead.
+func (*AddRequest) Descriptor() ([]byte, []int) {
+	return file_knn_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AddRequest) GetDocument() *Document {
+	if x != nil {
+		return x.Document
+	}
+	return nil
+}
+
+type AddResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddResponse) Reset() {
+	*x = AddResponse{}
+	mi := &file_knn_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddResponse) ProtoMessage() {}
+
+func (x *AddResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_knn_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddResponse.ProtoReflect.Descriptor instead.
+func (*AddResponse) Descriptor() ([]byte, []int) {
+	return file_knn_proto_rawDescGZIP(), []int{3}
+}
+
+type AddError struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// index is the position of the document in the stream
+	Index         int64  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Id            string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Message       string `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddError) Reset() {
+	*x = AddError{}
+	mi := &file_knn_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddError) ProtoMessage() {}
+
+func (x *AddError) ProtoReflect() protoreflect.Message {
+	mi := &file_knn_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddError.ProtoReflect.Descriptor instead.
+func (*AddError) Descriptor() ([]byte, []int) {
+	return file_knn_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *AddError) GetIndex() int64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *AddError) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *AddError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type AddStreamResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NumAdded      int64                  `protobuf:"varint,1,opt,name=num_added,json=numAdded,proto3" json:"num_added,omitempty"`
+	Errors        []*AddError            `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddStreamResponse) Reset() {
+	*x = AddStreamResponse{}
+	mi := &file_knn_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddStreamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddStreamResponse) ProtoMessage() {}
+
+func (x *AddStreamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_knn_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddStreamResponse.ProtoReflect.Descriptor instead.
+func (*AddStreamResponse) Descriptor() ([]byte, []int) {
+	return file_knn_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *AddStreamResponse) GetNumAdded() int64 {
+	if x != nil {
+		return x.NumAdded
+	}
+	return 0
+}
+
+func (x *AddStreamResponse) GetErrors() []*AddError {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+type DeleteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRequest) Reset() {
+	*x = DeleteRequest{}
+	mi := &file_knn_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRequest) ProtoMessage() {}
+
+func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_knn_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRequest.ProtoReflect.Descriptor instead.
+func (*DeleteRequest) Descriptor() ([]byte, []int) {
+	return file_knn_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DeleteRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DeleteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteResponse) Reset() {
+	*x = DeleteResponse{}
+	mi := &file_knn_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteResponse) ProtoMessage() {}
+
+func (x *DeleteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_knn_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteResponse.ProtoReflect.Descriptor instead.
+func (*DeleteResponse) Descriptor() ([]byte, []int) {
+	return file_knn_proto_rawDescGZIP(), []int{7}
+}
+
+type GetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRequest) Reset() {
+	*x = GetRequest{}
+	mi := &file_knn_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRequest) ProtoMessage() {}
+
+func (x *GetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_knn_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRequest.ProtoReflect.Descriptor instead.
+func (*GetRequest) Descriptor() ([]byte, []int) {
+	return file_knn_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type QueryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Vector        []float64              `protobuf:"fixed64,1,rep,packed,name=vector,proto3" json:"vector,omitempty"`
+	K             int32                  `protobuf:"varint,2,opt,name=k,proto3" json:"k,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryRequest) Reset() {
+	*x = QueryRequest{}
+	mi := &file_knn_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryRequest) ProtoMessage() {}
+
+func (x *QueryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_knn_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryRequest.ProtoReflect.Descriptor instead.
+func (*QueryRequest) Descriptor() ([]byte, []int) {
+	return file_knn_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *QueryRequest) GetVector() []float64 {
+	if x != nil {
+		return x.Vector
+	}
+	return nil
+}
+
+func (x *QueryRequest) GetK() int32 {
+	if x != nil {
+		return x.K
+	}
+	return 0
+}
+
+type QueryByIDRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	K             int32                  `protobuf:"varint,2,opt,name=k,proto3" json:"k,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryByIDRequest) Reset() {
+	*x = QueryByIDRequest{}
+	mi := &file_knn_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryByIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryByIDRequest) ProtoMessage() {}
+
+func (x *QueryByIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_knn_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryByIDRequest.ProtoReflect.Descriptor instead.
+func (*QueryByIDRequest) Descriptor() ([]byte, []int) {
+	return file_knn_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *QueryByIDRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *QueryByIDRequest) GetK() int32 {
+	if x != nil {
+		return x.K
+	}
+	return 0
+}
+
+type StatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatsRequest) Reset() {
+	*x = StatsRequest{}
+	mi := &file_knn_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatsRequest) ProtoMessage() {}
+
+func (x *StatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_knn_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatsRequest.ProtoReflect.Descriptor instead.
+func (*StatsRequest) Descriptor() ([]byte, []int) {
+	return file_knn_proto_rawDescGZIP(), []int{11}
+}
+
+type TableStats struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	NumBuckets        int64                  `protobuf:"varint,1,opt,name=num_buckets,json=numBuckets,proto3" json:"num_buckets,omitempty"`
+	LargestBucketSize int64                  `protobuf:"varint,2,opt,name=largest_bucket_size,json=largestBucketSize,proto3" json:"largest_bucket_size,omitempty"`
+	NumSplitBuckets   int64                  `protobuf:"varint,3,opt,name=num_split_buckets,json=numSplitBuckets,proto3" json:"num_split_buckets,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *TableStats) Reset() {
+	*x = TableStats{}
+	mi := &file_knn_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TableStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TableStats) ProtoMessage() {}
+
+func (x *TableStats) ProtoReflect() protoreflect.Message {
+	mi := &file_knn_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TableStats.ProtoReflect.Descriptor instead.
+func (*TableStats) Descriptor() ([]byte, []int) {
+	return file_knn_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *TableStats) GetNumBuckets() int64 {
+	if x != nil {
+		return x.NumBuckets
+	}
+	return 0
+}
+
+func (x *TableStats) GetLargestBucketSize() int64 {
+	if x != nil {
+		return x.LargestBucketSize
+	}
+	return 0
+}
+
+func (x *TableStats) GetNumSplitBuckets() int64 {
+	if x != nil {
+		return x.NumSplitBuckets
+	}
+	return 0
+}
+
+type StatsResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	NumDocuments   int64                  `protobuf:"varint,1,opt,name=num_documents,json=numDocuments,proto3" json:"num_documents,omitempty"`
+	HashTableBytes int64                  `protobuf:"varint,2,opt,name=hash_table_bytes,json=hashTableBytes,proto3" json:"hash_table_bytes,omitempty"`
+	BucketBytes    int64                  `protobuf:"varint,3,opt,name=bucket_bytes,json=bucketBytes,proto3" json:"bucket_bytes,omitempty"`
+	DocumentBytes  int64                  `protobuf:"varint,4,opt,name=document_bytes,json=documentBytes,proto3" json:"document_bytes,omitempty"`
+	VectorBytes    int64                  `protobuf:"varint,5,opt,name=vector_bytes,json=vectorBytes,proto3" json:"vector_bytes,omitempty"`
+	TotalBytes     int64                  `protobuf:"varint,6,opt,name=total_bytes,json=totalBytes,proto3" json:"total_bytes,omitempty"`
+	NumEvictions   uint64                 `protobuf:"varint,7,opt,name=num_evictions,json=numEvictions,proto3" json:"num_evictions,omitempty"`
+	Tables         []*TableStats          `protobuf:"bytes,8,rep,name=tables,proto3" json:"tables,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *StatsResponse) Reset() {
+	*x = StatsResponse{}
+	mi := &file_knn_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatsResponse) ProtoMessage() {}
+
+func (x *StatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_knn_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatsResponse.ProtoReflect.Descriptor instead.
+func (*StatsResponse) Descriptor() ([]byte, []int) {
+	return file_knn_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *StatsResponse) GetNumDocuments() int64 {
+	if x != nil {
+		return x.NumDocuments
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetHashTableBytes() int64 {
+	if x != nil {
+		return x.HashTableBytes
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetBucketBytes() int64 {
+	if x != nil {
+		return x.BucketBytes
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetDocumentBytes() int64 {
+	if x != nil {
+		return x.DocumentBytes
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetVectorBytes() int64 {
+	if x != nil {
+		return x.VectorBytes
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetTotalBytes() int64 {
+	if x != nil {
+		return x.TotalBytes
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetNumEvictions() uint64 {
+	if x != nil {
+		return x.NumEvictions
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetTables() []*TableStats {
+	if x != nil {
+		return x.Tables
+	}
+	return nil
+}
+
+var File_knn_proto protoreflect.FileDescriptor
+
+const file_knn_proto_rawDesc = "" +
+	"\n" +
+	"\tknn.proto\x12\x06knn.v1\x1a\x1cgoogle/protobuf/struct.proto\"g\n" +
+	"\bDocument\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06vector\x18\x02 \x03(\x01R\x06vector\x123\n" +
+	"\bmetadata\x18\x03 \x01(\v2\x17.google.protobuf.StructR\bmetadata\"Z\n" +
+	"\x0eResultDocument\x12,\n" +
+	"\bdocument\x18\x01 \x01(\v2\x10.knn.v1.DocumentR\bdocument\x12\x1a\n" +
+	"\bdistance\x18\x02 \x01(\x01R\bdistance\":\n" +
+	"\n" +
+	"AddRequest\x12,\n" +
+	"\bdocument\x18\x01 \x01(\v2\x10.knn.v1.DocumentR\bdocument\"\r\n" +
+	"\vAddResponse\"J\n" +
+	"\bAddError\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x03R\x05index\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\"Z\n" +
+	"\x11AddStreamResponse\x12\x1b\n" +
+	"\tnum_added\x18\x01 \x01(\x03R\bnumAdded\x12(\n" +
+	"\x06errors\x18\x02 \x03(\v2\x10.knn.v1.AddErrorR\x06errors\"\x1f\n" +
+	"\rDeleteRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x10\n" +
+	"\x0eDeleteResponse\"\x1c\n" +
+	"\n" +
+	"GetRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"4\n" +
+	"\fQueryRequest\x12\x16\n" +
+	"\x06vector\x18\x01 \x03(\x01R\x06vector\x12\f\n" +
+	"\x01k\x18\x02 \x01(\x05R\x01k\"0\n" +
+	"\x10QueryByIDRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\f\n" +
+	"\x01k\x18\x02 \x01(\x05R\x01k\"\x0e\n" +
+	"\fStatsRequest\"\x89\x01\n" +
+	"\n" +
+	"TableStats\x12\x1f\n" +
+	"\vnum_buckets\x18\x01 \x01(\x03R\n" +
+	"numBuckets\x12.\n" +
+	"\x13largest_bucket_size\x18\x02 \x01(\x03R\x11largestBucketSize\x12*\n" +
+	"\x11num_split_buckets\x18\x03 \x01(\x03R\x0fnumSplitBuckets\"\xbd\x02\n" +
+	"\rStatsResponse\x12#\n" +
+	"\rnum_documents\x18\x01 \x01(\x03R\fnumDocuments\x12(\n" +
+	"\x10hash_table_bytes\x18\x02 \x01(\x03R\x0ehashTableBytes\x12!\n" +
+	"\fbucket_bytes\x18\x03 \x01(\x03R\vbucketBytes\x12%\n" +
+	"\x0edocument_bytes\x18\x04 \x01(\x03R\rdocumentBytes\x12!\n" +
+	"\fvector_bytes\x18\x05 \x01(\x03R\vvectorBytes\x12\x1f\n" +
+	"\vtotal_bytes\x18\x06 \x01(\x03R\n" +
+	"totalBytes\x12#\n" +
+	"\rnum_evictions\x18\a \x01(\x04R\fnumEvictions\x12*\n" +
+	"\x06tables\x18\b \x03(\v2\x12.knn.v1.TableStatsR\x06tables2\x89\x03\n" +
+	"\x03KNN\x12.\n" +
+	"\x03Add\x12\x12.knn.v1.AddRequest\x1a\x13.knn.v1.AddResponse\x12<\n" +
+	"\tAddStream\x12\x12.knn.v1.AddRequest\x1a\x19.knn.v1.AddStreamResponse(\x01\x127\n" +
+	"\x06Delete\x12\x15.knn.v1.DeleteRequest\x1a\x16.knn.v1.DeleteResponse\x12+\n" +
+	"\x03Get\x12\x12.knn.v1.GetRequest\x1a\x10.knn.v1.Document\x127\n" +
+	"\x05Query\x12\x14.knn.v1.QueryRequest\x1a\x16.knn.v1.ResultDocument0\x01\x12?\n" +
+	"\tQueryByID\x12\x18.knn.v1.QueryByIDRequest\x1a\x16.knn.v1.ResultDocument0\x01\x124\n" +
+	"\x05Stats\x12\x14.knn.v1.StatsRequest\x1a\x15.knn.v1.StatsResponseBN\n" +
+	"\x1acom.github.riandyrn.knn.v1P\x01Z.github.com/riandyrn/go-knn/knngrpc/knnpb;knnpbb\x06proto3"
+
+var (
+	file_knn_proto_rawDescOnce sync.Once
+	file_knn_proto_rawDescData []byte
+)
+
+func file_knn_proto_rawDescGZIP() []byte {
+	file_knn_proto_rawDescOnce.Do(func() {
+		file_knn_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_knn_proto_rawDesc), len(file_knn_proto_rawDesc)))
+	})
+	return file_knn_proto_rawDescData
+}
+
+var file_knn_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_knn_proto_goTypes = []any{
+	(*Document)(nil),          // 0: knn.v1.Document
+	(*ResultDocument)(nil),    // 1: knn.v1.ResultDocument
+	(*AddRequest)(nil),        // 2: knn.v1.AddRequest
+	(*AddResponse)(nil),       // 3: knn.v1.AddResponse
+	(*AddError)(nil),          // 4: knn.v1.AddError
+	(*AddStreamResponse)(nil), // 5: knn.v1.AddStreamResponse
+	(*DeleteRequest)(nil),     // 6: knn.v1.DeleteRequest
+	(*DeleteResponse)(nil),    // 7: knn.v1.DeleteResponse
+	(*GetRequest)(nil),        // 8: knn.v1.GetRequest
+	(*QueryRequest)(nil),      // 9: knn.v1.QueryRequest
+	(*QueryByIDRequest)(nil),  // 10: knn.v1.QueryByIDRequest
+	(*StatsRequest)(nil),      // 11: knn.v1.StatsRequest
+	(*TableStats)(nil),        // 12: knn.v1.TableStats
+	(*StatsResponse)(nil),     // 13: knn.v1.StatsResponse
+	(*structpb.Struct)(nil),   // 14: google.protobuf.Struct
+}
+var file_knn_proto_depIdxs = []int32{
+	14, // 0: knn.v1.Document.metadata:type_name -> google.protobuf.Struct
+	0,  // 1: knn.v1.ResultDocument.document:type_name -> knn.v1.Document
+	0,  // 2: knn.v1.AddRequest.document:type_name -> knn.v1.Document
+	4,  // 3: knn.v1.AddStreamResponse.errors:type_name -> knn.v1.AddError
+	12, // 4: knn.v1.StatsResponse.tables:type_name -> knn.v1.TableStats
+	2,  // 5: knn.v1.KNN.Add:input_type -> knn.v1.AddRequest
+	2,  // 6: knn.v1.KNN.AddStream:input_type -> knn.v1.AddRequest
+	6,  // 7: knn.v1.KNN.Delete:input_type -> knn.v1.DeleteRequest
+	8,  // 8: knn.v1.KNN.Get:input_type -> knn.v1.GetRequest
+	9,  // 9: knn.v1.KNN.Query:input_type -> knn.v1.QueryRequest
+	10, // 10: knn.v1.KNN.QueryByID:input_type -> knn.v1.QueryByIDRequest
+	11, // 11: knn.v1.KNN.Stats:input_type -> knn.v1.StatsRequest
+	3,  // 12: knn.v1.KNN.Add:output_type -> knn.v1.AddResponse
+	5,  // 13: knn.v1.KNN.AddStream:output_type -> knn.v1.AddStreamResponse
+	7,  // 14: knn.v1.KNN.Delete:output_type -> knn.v1.DeleteResponse
+	0,  // 15: knn.v1.KNN.Get:output_type -> knn.v1.Document
+	1,  // 16: knn.v1.KNN.Query:output_type -> knn.v1.ResultDocument
+	1,  // 17: knn.v1.KNN.QueryByID:output_type -> knn.v1.ResultDocument
+	13, // 18: knn.v1.KNN.Stats:output_type -> knn.v1.StatsResponse
+	12, // [12:19] is the sub-list for method output_type
+	5,  // [5:12] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_knn_proto_init() }
+func file_knn_proto_init() {
+	if File_knn_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_knn_proto_rawDesc), len(file_knn_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_knn_proto_goTypes,
+		DependencyIndexes: file_knn_proto_depIdxs,
+		MessageInfos:      file_knn_proto_msgTypes,
+	}.Build()
+	File_knn_proto = out.File
+	file_knn_proto_goTypes = nil
+	file_knn_proto_depIdxs = nil
+}
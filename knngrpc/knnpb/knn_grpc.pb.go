@@ -0,0 +1,383 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: knn.proto
+
+package knnpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	KNN_Add_FullMethodName       = "/knn.v1.KNN/Add"
+	KNN_AddStream_FullMethodName = "/knn.v1.KNN/AddStream"
+	KNN_Delete_FullMethodName    = "/knn.v1.KNN/Delete"
+	KNN_Get_FullMethodName       = "/knn.v1.KNN/Get"
+	KNN_Query_FullMethodName     = "/knn.v1.KNN/Query"
+	KNN_QueryByID_FullMethodName = "/knn.v1.KNN/QueryByID"
+	KNN_Stats_FullMethodName     = "/knn.v1.KNN/Stats"
+)
+
+// KNNClient is the client API for KNN service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// KNN exposes single KNN index. The errors of the index are mapped
+// into status codes, e.g NOT_FOUND for missing document & INVALID_ARGUMENT
+// for invalid document or query.
+type KNNClient interface {
+	// Add adds document to the index, the existing document with
+	// the same id is replaced.
+	Add(ctx context.Context, in *AddRequest, opts ...grpc.CallOption) (*AddResponse, error)
+	// AddStream adds documents sent by the client until the client
+	// closes the stream. Invalid document doesn't abort the stream,
+	// its error is reported in the response instead.
+	AddStream(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[AddRequest, AddStreamResponse], error)
+	// Delete deletes document from the index.
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	// Get returns single document from the index.
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Document, error)
+	// Query streams the documents nearest to the vector, sorted from
+	// the most similar.
+	Query(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ResultDocument], error)
+	// QueryByID streams the documents nearest to the document in the
+	// index, sorted from the most similar. The document itself is
+	// excluded.
+	QueryByID(ctx context.Context, in *QueryByIDRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ResultDocument], error)
+	// Stats returns statistics of the index.
+	Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error)
+}
+
+type kNNClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewKNNClient(cc grpc.ClientConnInterface) KNNClient {
+	return &kNNClient{cc}
+}
+
+func (c *kNNClient) Add(ctx context.Context, in *AddRequest, opts ...grpc.CallOption) (*AddResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddResponse)
+	err := c.cc.Invoke(ctx, KNN_Add_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kNNClient) AddStream(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[AddRequest, AddStreamResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &KNN_ServiceDesc.Streams[0], KNN_AddStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[AddRequest, AddStreamResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type KNN_AddStreamClient = grpc.ClientStreamingClient[AddRequest, AddStreamResponse]
+
+func (c *kNNClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteResponse)
+	err := c.cc.Invoke(ctx, KNN_Delete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kNNClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Document, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Document)
+	err := c.cc.Invoke(ctx, KNN_Get_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kNNClient) Query(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ResultDocument], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &KNN_ServiceDesc.Streams[1], KNN_Query_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[QueryRequest, ResultDocument]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type KNN_QueryClient = grpc.ServerStreamingClient[ResultDocument]
+
+func (c *kNNClient) QueryByID(ctx context.Context, in *QueryByIDRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ResultDocument], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &KNN_ServiceDesc.Streams[2], KNN_QueryByID_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[QueryByIDRequest, ResultDocument]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type KNN_QueryByIDClient = grpc.ServerStreamingClient[ResultDocument]
+
+func (c *kNNClient) Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StatsResponse)
+	err := c.cc.Invoke(ctx, KNN_Stats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// KNNServer is the server API for KNN service.
+// All implementations must embed UnimplementedKNNServer
+// for forward compatibility.
+//
+// KNN exposes single KNN index. The errors of the index are mapped
+// into status codes, e.g NOT_FOUND for missing document & INVALID_ARGUMENT
+// for invalid document or query.
+type KNNServer interface {
+	// Add adds document to the index, the existing document with
+	// the same id is replaced.
+	Add(context.Context, *AddRequest) (*AddResponse, error)
+	// AddStream adds documents sent by the client until the client
+	// closes the stream. Invalid document doesn't abort the stream,
+	// its error is reported in the response instead.
+	AddStream(grpc.ClientStreamingServer[AddRequest, AddStreamResponse]) error
+	// Delete deletes document from the index.
+	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	// Get returns single document from the index.
+	Get(context.Context, *GetRequest) (*Document, error)
+	// Query streams the documents nearest to the vector, sorted from
+	// the most similar.
+	Query(*QueryRequest, grpc.ServerStreamingServer[ResultDocument]) error
+	// QueryByID streams the documents nearest to the document in the
+	// index, sorted from the most similar. The document itself is
+	// excluded.
+	QueryByID(*QueryByIDRequest, grpc.ServerStreamingServer[ResultDocument]) error
+	// Stats returns statistics of the index.
+	Stats(context.Context, *StatsRequest) (*StatsResponse, error)
+	mustEmbedUnimplementedKNNServer()
+}
+
+// UnimplementedKNNServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedKNNServer struct{}
+
+func (UnimplementedKNNServer) Add(context.Context, *AddRequest) (*AddResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Add not implemented")
+}
+func (UnimplementedKNNServer) AddStream(grpc.ClientStreamingServer[AddRequest, AddStreamResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method AddStream not implemented")
+}
+func (UnimplementedKNNServer) Delete(context.Context, *DeleteRequest) (*DeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedKNNServer) Get(context.Context, *GetRequest) (*Document, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedKNNServer) Query(*QueryRequest, grpc.ServerStreamingServer[ResultDocument]) error {
+	return status.Errorf(codes.Unimplemented, "method Query not implemented")
+}
+func (UnimplementedKNNServer) QueryByID(*QueryByIDRequest, grpc.ServerStreamingServer[ResultDocument]) error {
+	return status.Errorf(codes.Unimplemented, "method QueryByID not implemented")
+}
+func (UnimplementedKNNServer) Stats(context.Context, *StatsRequest) (*StatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stats not implemented")
+}
+func (UnimplementedKNNServer) mustEmbedUnimplementedKNNServer() {}
+func (UnimplementedKNNServer) testEmbeddedByValue()             {}
+
+// UnsafeKNNServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to KNNServer will
+// result in compilation errors.
+type UnsafeKNNServer interface {
+	mustEmbedUnimplementedKNNServer()
+}
+
+func RegisterKNNServer(s grpc.ServiceRegistrar, srv KNNServer) {
+	// If the following call pancis, it indicates UnimplementedKNNServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&KNN_ServiceDesc, srv)
+}
+
+func _KNN_Add_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KNNServer).Add(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KNN_Add_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KNNServer).Add(ctx, req.(*AddRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KNN_AddStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(KNNServer).AddStream(&grpc.GenericServerStream[AddRequest, AddStreamResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type KNN_AddStreamServer = grpc.ClientStreamingServer[AddRequest, AddStreamResponse]
+
+func _KNN_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KNNServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KNN_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KNNServer).Delete(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KNN_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KNNServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KNN_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KNNServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KNN_Query_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(QueryRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(KNNServer).Query(m, &grpc.GenericServerStream[QueryRequest, ResultDocument]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type KNN_QueryServer = grpc.ServerStreamingServer[ResultDocument]
+
+func _KNN_QueryByID_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(QueryByIDRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(KNNServer).QueryByID(m, &grpc.GenericServerStream[QueryByIDRequest, ResultDocument]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type KNN_QueryByIDServer = grpc.ServerStreamingServer[ResultDocument]
+
+func _KNN_Stats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KNNServer).Stats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KNN_Stats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KNNServer).Stats(ctx, req.(*StatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// KNN_ServiceDesc is the grpc.ServiceDesc for KNN service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var KNN_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "knn.v1.KNN",
+	HandlerType: (*KNNServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Add",
+			Handler:    _KNN_Add_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _KNN_Delete_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _KNN_Get_Handler,
+		},
+		{
+			MethodName: "Stats",
+			Handler:    _KNN_Stats_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "AddStream",
+			Handler:       _KNN_AddStream_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Query",
+			Handler:       _KNN_Query_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "QueryByID",
+			Handler:       _KNN_QueryByID_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "knn.proto",
+}
@@ -0,0 +1,196 @@
+// Package knngrpc exposes KNN index through gRPC service defined in
+// proto/knn.proto, so it could be used by services written in other
+// languages (e.g Java). The Go code of the service in knnpb package
+// is generated from the proto file, regenerate it after changing the
+// proto file by running `go generate`.
+package knngrpc
+
+//go:generate protoc -I proto --go_out=knnpb --go_opt=paths=source_relative --go-grpc_out=knnpb --go-grpc_opt=paths=source_relative knn.proto
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/riandyrn/go-knn"
+	"github.com/riandyrn/go-knn/knngrpc/knnpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Server implements knnpb.KNNServer on top of KNN index, register it
+// to grpc server using knnpb.RegisterKNNServer(). The documents added
+// through the server are stored as knn.BasicDocument.
+type Server struct {
+	knnpb.UnimplementedKNNServer
+	index *knn.KNN
+}
+
+// NewServer returns server which serves `index`
+func NewServer(index *knn.KNN) *Server {
+	return &Server{index: index}
+}
+
+// Add adds single document to the index
+func (s *Server) Add(ctx context.Context, req *knnpb.AddRequest) (*knnpb.AddResponse, error) {
+	if err := s.index.Add(fromPBDocument(req.GetDocument())); err != nil {
+		return nil, toStatus(err)
+	}
+	return &knnpb.AddResponse{}, nil
+}
+
+// AddStream adds documents sent by the client until the client
+// closes the stream, the invalid documents are reported in the
+// response without aborting the stream
+func (s *Server) AddStream(stream knnpb.KNN_AddStreamServer) error {
+	resp := &knnpb.AddStreamResponse{}
+	for i := int64(0); ; i++ {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(resp)
+		}
+		if err != nil {
+			return err
+		}
+		doc := req.GetDocument()
+		if err := s.index.Add(fromPBDocument(doc)); err != nil {
+			// the index is full, so the next documents
+			// would fail as well
+			if errors.Is(err, knn.ErrMemoryLimit) {
+				return toStatus(err)
+			}
+			resp.Errors = append(resp.Errors, &knnpb.AddError{
+				Index:   i,
+				Id:      doc.GetId(),
+				Message: err.Error(),
+			})
+			continue
+		}
+		resp.NumAdded++
+	}
+}
+
+// Delete deletes single document from the index
+func (s *Server) Delete(ctx context.Context, req *knnpb.DeleteRequest) (*knnpb.DeleteResponse, error) {
+	if err := s.index.Delete(req.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &knnpb.DeleteResponse{}, nil
+}
+
+// Get returns single document from the index
+func (s *Server) Get(ctx context.Context, req *knnpb.GetRequest) (*knnpb.Document, error) {
+	doc, err := s.index.Get(req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toPBDocument(doc)
+}
+
+// Query streams the documents nearest to the vector in `req`
+func (s *Server) Query(req *knnpb.QueryRequest, stream knnpb.KNN_QueryServer) error {
+	resultDocs, err := s.index.Query(req.GetVector(), int(req.GetK()))
+	if err != nil {
+		return toStatus(err)
+	}
+	return sendResultDocs(resultDocs, stream.Send)
+}
+
+// QueryByID streams the documents nearest to the document in `req`
+func (s *Server) QueryByID(req *knnpb.QueryByIDRequest, stream knnpb.KNN_QueryByIDServer) error {
+	resultDocs, err := s.index.QueryByID(req.GetId(), int(req.GetK()))
+	if err != nil {
+		return toStatus(err)
+	}
+	return sendResultDocs(resultDocs, stream.Send)
+}
+
+// Stats returns statistics of the index
+func (s *Server) Stats(ctx context.Context, req *knnpb.StatsRequest) (*knnpb.StatsResponse, error) {
+	stats := s.index.Stats()
+	resp := &knnpb.StatsResponse{
+		NumDocuments:   int64(stats.NumDocuments),
+		HashTableBytes: stats.HashTableBytes,
+		BucketBytes:    stats.BucketBytes,
+		DocumentBytes:  stats.DocumentBytes,
+		VectorBytes:    stats.VectorBytes,
+		TotalBytes:     stats.TotalBytes,
+		NumEvictions:   stats.NumEvictions,
+	}
+	for _, table := range stats.Tables {
+		resp.Tables = append(resp.Tables, &knnpb.TableStats{
+			NumBuckets:        int64(table.NumBuckets),
+			LargestBucketSize: int64(table.LargestBucketSize),
+			NumSplitBuckets:   int64(table.NumSplitBuckets),
+		})
+	}
+	return resp, nil
+}
+
+// sendResultDocs sends dense documents in `resultDocs` using `send`,
+// the sparse documents are skipped since the service doesn't
+// support them
+func sendResultDocs(resultDocs []knn.ResultDocument, send func(*knnpb.ResultDocument) error) error {
+	for _, resultDoc := range resultDocs {
+		if resultDoc.Document == nil {
+			continue
+		}
+		doc, err := toPBDocument(resultDoc.Document)
+		if err != nil {
+			return err
+		}
+		if err := send(&knnpb.ResultDocument{Document: doc, Distance: resultDoc.Distance}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fromPBDocument converts `doc` into knn.BasicDocument, it returns
+// nil when `doc` is nil so the index rejects it
+func fromPBDocument(doc *knnpb.Document) knn.Document {
+	if doc == nil {
+		return nil
+	}
+	basicDoc := &knn.BasicDocument{ID: doc.GetId(), Vector: doc.GetVector()}
+	if doc.GetMetadata() != nil {
+		basicDoc.Metadata = doc.GetMetadata().AsMap()
+	}
+	return basicDoc
+}
+
+// toPBDocument converts `doc` into knnpb.Document, the metadata is
+// only set when the document implements knn.MetadataDocument
+func toPBDocument(doc knn.Document) (*knnpb.Document, error) {
+	if doc == nil {
+		return nil, nil
+	}
+	pbDoc := &knnpb.Document{Id: doc.GetID(), Vector: doc.GetVector()}
+	if m, ok := doc.(knn.MetadataDocument); ok && m.GetMetadata() != nil {
+		metadata, err := structpb.NewStruct(m.GetMetadata())
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "unable to convert metadata of %v due: %v", doc.GetID(), err)
+		}
+		pbDoc.Metadata = metadata
+	}
+	return pbDoc, nil
+}
+
+// toStatus converts error returned by the index into status error
+func toStatus(err error) error {
+	code := codes.Internal
+	switch {
+	case errors.Is(err, knn.ErrNotFound):
+		code = codes.NotFound
+	case errors.Is(err, knn.ErrMemoryLimit):
+		code = codes.ResourceExhausted
+	case errors.Is(err, knn.ErrInvalidDocument),
+		errors.Is(err, knn.ErrDimensionMismatch),
+		errors.Is(err, knn.ErrEmptyVector),
+		errors.Is(err, knn.ErrInvalidVector),
+		errors.Is(err, knn.ErrInvalidK):
+		code = codes.InvalidArgument
+	}
+	return status.Error(code, err.Error())
+}
@@ -0,0 +1,197 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/riandyrn/go-knn"
+	"github.com/riandyrn/go-knn/knngrpc"
+	"github.com/riandyrn/go-knn/knngrpc/knnpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestClient serves `index` over in-process connection & returns
+// client connected to it, call the returned function to stop serving
+func newTestClient(t *testing.T, index *knn.KNN) (*knngrpc.Client, func()) {
+	ln := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	knnpb.RegisterKNNServer(srv, knngrpc.NewServer(index))
+	go srv.Serve(ln)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return ln.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("unable to connect due: %v", err)
+	}
+	return knngrpc.NewClient(conn), func() {
+		conn.Close()
+		srv.Stop()
+	}
+}
+
+func getRandomVector(dim int) []float64 {
+	vector := make([]float64, 0, dim)
+	for j := 0; j < dim; j++ {
+		vector = append(vector, rand.NormFloat64())
+	}
+	return vector
+}
+
+func TestClient(t *testing.T) {
+	// prepare index, use single hyperplane with large slot
+	// so all documents land on the same bucket
+	index := knn.NewKNN(knn.Configs{
+		VectorDimension: 2,
+		NumHashTable:    1,
+		NumHyperplane:   1,
+		SlotSize:        1000000,
+	})
+	client, stop := newTestClient(t, index)
+	defer stop()
+	ctx := context.Background()
+
+	// add documents
+	docs := []*knn.BasicDocument{
+		{ID: "doc_1", Vector: []float64{0, 0}, Metadata: map[string]interface{}{"label": "cat"}},
+		{ID: "doc_2", Vector: []float64{0, 1}},
+		{ID: "doc_3", Vector: []float64{0, 3}},
+	}
+	for _, doc := range docs {
+		if err := client.Add(ctx, doc); err != nil {
+			t.Fatalf("unable to add document due: %v", err)
+		}
+	}
+	err := client.Add(ctx, &knn.BasicDocument{ID: "doc_4", Vector: []float64{0}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("unexpected error on dimension mismatch, got: %v", err)
+	}
+
+	// get document
+	doc, err := client.Get(ctx, "doc_1")
+	if err != nil {
+		t.Fatalf("unable to get document due: %v", err)
+	}
+	if !reflect.DeepEqual(doc, docs[0]) {
+		t.Fatalf("unexpected document, expected: %+v, got: %+v", docs[0], doc)
+	}
+	if _, err := client.Get(ctx, "doc_4"); !errors.Is(err, knn.ErrNotFound) {
+		t.Fatalf("unexpected error on missing document, got: %v", err)
+	}
+
+	// query
+	testCases := []struct {
+		Name   string
+		Query  func() ([]knn.ResultDocument, error)
+		ExpIDs []string
+	}{
+		{
+			Name: "Test Query",
+			Query: func() ([]knn.ResultDocument, error) {
+				return client.Query(ctx, []float64{0, 0.9}, 2)
+			},
+			ExpIDs: []string{"doc_2", "doc_1"},
+		},
+		{
+			Name: "Test Query By ID",
+			Query: func() ([]knn.ResultDocument, error) {
+				return client.QueryByID(ctx, "doc_3", 2)
+			},
+			ExpIDs: []string{"doc_2", "doc_1"},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			resultDocs, err := testCase.Query()
+			if err != nil {
+				t.Fatalf("unable to query due: %v", err)
+			}
+			if len(resultDocs) != len(testCase.ExpIDs) {
+				t.Fatalf("unexpected number of results, expected: %v, got: %v", len(testCase.ExpIDs), len(resultDocs))
+			}
+			for i, resultDoc := range resultDocs {
+				if resultDoc.Document.GetID() != testCase.ExpIDs[i] {
+					t.Fatalf("unexpected result at %v, expected: %v, got: %v", i, testCase.ExpIDs[i], resultDoc.Document.GetID())
+				}
+			}
+		})
+	}
+	if _, err := client.Query(ctx, []float64{0, 0}, 0); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("unexpected error on invalid k, got: %v", err)
+	}
+
+	// delete document
+	if err := client.Delete(ctx, "doc_1"); err != nil {
+		t.Fatalf("unable to delete document due: %v", err)
+	}
+	if err := client.Delete(ctx, "doc_1"); !errors.Is(err, knn.ErrNotFound) {
+		t.Fatalf("unexpected error on deleting missing document, got: %v", err)
+	}
+
+	// check stats
+	stats, err := client.Stats(ctx)
+	if err != nil {
+		t.Fatalf("unable to get stats due: %v", err)
+	}
+	if stats.NumDocuments != 2 || len(stats.Tables) != 1 {
+		t.Fatalf("unexpected stats, got: %+v", stats)
+	}
+}
+
+func TestAddStream(t *testing.T) {
+	// prepare index
+	dim := 10
+	index := knn.NewKNN(knn.Configs{
+		VectorDimension: dim,
+		NumHashTable:    2,
+		NumHyperplane:   2,
+		SlotSize:        5,
+	})
+	client, stop := newTestClient(t, index)
+	defer stop()
+
+	// stream documents, every 10th document is invalid
+	n := 1000
+	docs := make(chan knn.Document)
+	go func() {
+		defer close(docs)
+		for i := 0; i < n; i++ {
+			vector := getRandomVector(dim)
+			if i%10 == 0 {
+				vector = vector[:dim-1]
+			}
+			docs <- &knn.BasicDocument{ID: fmt.Sprintf("doc_%v", i), Vector: vector}
+		}
+	}()
+	resp, err := client.AddStream(context.Background(), docs)
+	if err != nil {
+		t.Fatalf("unable to stream documents due: %v", err)
+	}
+	if resp.GetNumAdded() != int64(n-n/10) {
+		t.Fatalf("unexpected number of added documents, expected: %v, got: %v", n-n/10, resp.GetNumAdded())
+	}
+	if len(resp.GetErrors()) != n/10 {
+		t.Fatalf("unexpected number of errors, expected: %v, got: %v", n/10, len(resp.GetErrors()))
+	}
+	for i, addErr := range resp.GetErrors() {
+		if addErr.GetIndex() != int64(i*10) {
+			t.Fatalf("unexpected index of error, expected: %v, got: %v", i*10, addErr.GetIndex())
+		}
+	}
+	if index.Len() != n-n/10 {
+		t.Fatalf("unexpected number of documents, expected: %v, got: %v", n-n/10, index.Len())
+	}
+}
@@ -0,0 +1,671 @@
+package knn
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// The basic LSH engine in this file is ported from
+// `github.com/riandyrn/lsh` (fork of `github.com/ekzhu/lsh`)
+// so it could hash sparse vectors without densifying them.
+//
+// Copyright (c) 2016, Eric Zhu and Charlie Mei. The original code
+// is distributed under the MIT License, see THIRD_PARTY_NOTICES.
+
+const lshRandSeed = 1
+
+// maxSplitDepth is the maximum number of times a bucket could be
+// split recursively, we need it because splitting bucket of
+// identical points never makes it smaller.
+const maxSplitDepth = 4
+
+// splitProbeFactor is the number of times maxBucketSize handles
+// probed in split bucket for single query, the sub buckets nearest
+// to the query are probed first.
+const splitProbeFactor = 4
+
+// bucket holds handles of documents which share the same hash
+// values. The handles are assigned by KNN, we use them instead of
+// document ids because they are much smaller to be duplicated in
+// all hash tables. We keep the hash values to tell apart buckets
+// which keys collide.
+//
+// When the bucket is split, its handles are moved into buckets of
+// `sub` which are keyed by hash values of extra hyperplanes. The
+// slot size of the extra hyperplanes is `subW`, it is taken from
+// the spread of the points in the bucket, so the bucket could be
+// split even when the points are much closer than the slot size
+// of the top level table.
+type bucket struct {
+	values  []int
+	handles []uint32
+	sub     hashTable
+	subW    float64
+}
+
+// hashTable maps 64-bit FNV-1a hash of the hash values to the
+// buckets, on collision the buckets are chained.
+type hashTable map[uint64][]*bucket
+
+// point is the input data of LSH, it is either dense or sparse
+type point struct {
+	dense   []float64
+	indices []int
+	values  []float64
+}
+
+// hashValues holds the hash values of a point for all tables.
+// The values of table i are in values[i*m : (i+1)*m], the keys
+// are the FNV-1a hash of them. We also keep the point, so the
+// hash values in split buckets could be calculated when needed.
+type hashValues struct {
+	values []int
+	keys   []uint64
+	point  point
+}
+
+// basicLsh implements the original LSH algorithm for L2 distance.
+type basicLsh struct {
+	// Dimensionality of the input data.
+	dim int
+	// Number of hash tables.
+	l int
+	// Number of hash functions for each table.
+	m int
+	// Shared constant for each table.
+	w float64
+
+	// Hash function params for each (l, m). When the hyperplanes
+	// are generated on the fly `a` is nil.
+	a [][][]float64
+	b [][]float64
+
+	// Hash tables.
+	tables []hashTable
+
+	// pool is used for updating the hash tables in parallel,
+	// nil pool means the tables are updated sequentially.
+	pool *workerPool
+
+	// numBuckets holds total number of buckets in all tables,
+	// it is updated atomically since the tables could be updated
+	// in parallel.
+	numBuckets int64
+
+	// When maxBucketSize is greater than 0, bucket which size
+	// exceeds it is split using `splitM` extra hyperplanes. The
+	// points of the handles in the bucket are taken from pointOf.
+	maxBucketSize int
+	splitM        int
+	pointOf       func(h uint32) point
+}
+
+// newBasicLsh creates a basic LSH for L2 distance. dim is the
+// dimensionality of the data, l is the number of hash tables to
+// use, m is the number of hash values to concatenate to form the
+// key to the hash tables, w is the slot size for the family of
+// LSH functions. When `onTheFly` is true, the hyperplanes are not
+// stored in memory but generated from hash of their position.
+// numWorkers is the number of goroutines used for updating the
+// hash tables, value less than 2 means sequential update.
+func newBasicLsh(dim, l, m int, w float64, onTheFly bool, numWorkers int) *basicLsh {
+	var a [][][]float64
+	if !onTheFly {
+		a = make([][][]float64, l)
+	}
+	b := make([][]float64, l)
+	random := rand.New(rand.NewSource(lshRandSeed))
+	for i := range b {
+		if a != nil {
+			a[i] = make([][]float64, m)
+		}
+		b[i] = make([]float64, m)
+		for j := range b[i] {
+			if a != nil {
+				a[i][j] = make([]float64, dim)
+				for d := 0; d < dim; d++ {
+					a[i][j][d] = random.NormFloat64()
+				}
+			}
+			b[i][j] = random.Float64() * w
+		}
+	}
+	tables := make([]hashTable, l)
+	for i := range tables {
+		tables[i] = make(hashTable)
+	}
+	return &basicLsh{
+		dim:    dim,
+		l:      l,
+		m:      m,
+		w:      w,
+		a:      a,
+		b:      b,
+		tables: tables,
+		pool:   newWorkerPool(numWorkers),
+	}
+}
+
+// enableSplit makes the LSH split bucket which size exceeds
+// `maxBucketSize` using `splitM` extra hyperplanes. pointOf is
+// used for getting the point of handles in the split bucket.
+func (index *basicLsh) enableSplit(maxBucketSize, splitM int, pointOf func(h uint32) point) {
+	index.maxBucketSize = maxBucketSize
+	index.splitM = splitM
+	index.pointOf = pointOf
+}
+
+// hyperplane returns coefficient of hyperplane (i, j) on
+// dimension d. The extra hyperplanes used for splitting
+// buckets (j >= m) are always generated on the fly.
+func (index *basicLsh) hyperplane(i, j, d int) float64 {
+	if index.a != nil && j < index.m {
+		return index.a[i][j][d]
+	}
+	// generate standard normal value using box-muller
+	// transform from hash of the position
+	x := mix64(mix64(mix64(uint64(i)+lshRandSeed)^uint64(j)) ^ uint64(d))
+	y := mix64(x)
+	u1 := (float64(x>>11) + 1) / (1 << 53)
+	u2 := float64(y>>11) / (1 << 53)
+	return math.Sqrt(-2*math.Log(u1)) * math.Cos(2*math.Pi*u2)
+}
+
+// offset returns offset of hyperplane (i, j)
+func (index *basicLsh) offset(i, j int) float64 {
+	if j < index.m {
+		return index.b[i][j]
+	}
+	// generate uniform value in [0, w) from hash of the position
+	x := mix64(mix64(mix64(uint64(i)+lshRandSeed)^uint64(j)) ^ math.MaxUint64)
+	return float64(x>>11) / (1 << 53) * index.w
+}
+
+// hashValue returns hash value of `p` on hyperplane (i, j)
+func (index *basicLsh) hashValue(p point, i, j int) int {
+	return int(math.Floor((index.project(p, i, j) + index.offset(i, j)) / index.w))
+}
+
+// project returns dot product of `p` & hyperplane (i, j)
+func (index *basicLsh) project(p point, i, j int) float64 {
+	dot := 0.0
+	if p.dense != nil {
+		for d, v := range p.dense {
+			dot += v * index.hyperplane(i, j, d)
+		}
+	} else {
+		// only iterates the non-zero values of sparse vector
+		for k, d := range p.indices {
+			dot += p.values[k] * index.hyperplane(i, j, d)
+		}
+	}
+	return dot
+}
+
+// hash returns all combined hash values for all hash tables.
+func (index *basicLsh) hash(vector []float64) hashValues {
+	return index.hashPoint(point{dense: vector})
+}
+
+// hashSparse is like hash but for sparse vector.
+func (index *basicLsh) hashSparse(indices []int, values []float64) hashValues {
+	return index.hashPoint(point{indices: indices, values: values})
+}
+
+func (index *basicLsh) hashPoint(p point) hashValues {
+	hvs := hashValues{
+		values: make([]int, index.l*index.m),
+		keys:   make([]uint64, index.l),
+		point:  p,
+	}
+	for i := 0; i < index.l; i++ {
+		for j := 0; j < index.m; j++ {
+			hvs.values[i*index.m+j] = index.hashValue(p, i, j)
+		}
+		hvs.keys[i] = hashKey(hvs.tableValues(i, index.m))
+	}
+	return hvs
+}
+
+// splitHyperplane returns index of the j-th extra hyperplane used
+// for sub table at `depth` (starts from 1).
+func (index *basicLsh) splitHyperplane(depth, j int) int {
+	return index.m + (depth-1)*index.splitM + j
+}
+
+// splitHash returns hash values of `p` along with their key for
+// sub table of split bucket in table i at `depth`, `w` is the slot
+// size of the sub table.
+func (index *basicLsh) splitHash(p point, i, depth int, w float64) ([]int, uint64) {
+	positions := index.splitPositions(p, i, depth, w)
+	values := make([]int, index.splitM)
+	for j, pos := range positions {
+		values[j] = int(math.Floor(pos))
+	}
+	return values, hashKey(values)
+}
+
+// splitPositions returns the positions of `p` in units of slot on
+// the extra hyperplanes of sub table in table i at `depth`, the
+// hash values of `p` are the floor of the positions.
+func (index *basicLsh) splitPositions(p point, i, depth int, w float64) []float64 {
+	positions := make([]float64, index.splitM)
+	for j := range positions {
+		hj := index.splitHyperplane(depth, j)
+		// scale the offset into the slot size of sub table
+		offset := index.offset(i, hj) / index.w * w
+		positions[j] = (index.project(p, i, hj) + offset) / w
+	}
+	return positions
+}
+
+// hashKey returns FNV-1a hash of `values`
+func hashKey(values []int) uint64 {
+	const (
+		offset64 = 14695981039346656037
+		prime64  = 1099511628211
+	)
+	key := uint64(offset64)
+	for _, v := range values {
+		// hash all 8 bytes of the value
+		u := uint64(v)
+		for b := uint(0); b < 64; b += 8 {
+			key ^= (u >> b) & 0xff
+			key *= prime64
+		}
+	}
+	return key
+}
+
+// tableValues returns the hash values for table i
+func (hvs hashValues) tableValues(i, m int) []int {
+	return hvs.values[i*m : (i+1)*m]
+}
+
+// lookup returns the bucket with `values` in `table`, it returns
+// nil when the bucket doesn't exist.
+func lookup(table hashTable, key uint64, values []int) *bucket {
+	for _, b := range table[key] {
+		if equalValues(b.values, values) {
+			return b
+		}
+	}
+	return nil
+}
+
+// probeSplit calls fn for the handles in sub table of split bucket
+// `b` in table i at `depth`, until fn returns false. The sub buckets
+// are probed in the order of their distance to point `p` on the
+// extra hyperplanes starting from the slot of `p`, so the neighbours
+// of `p` which fall into the sibling slots are still found.
+func (index *basicLsh) probeSplit(b *bucket, p point, i, depth int, fn func(h uint32) bool) bool {
+	positions := index.splitPositions(p, i, depth, b.subW)
+	type probe struct {
+		b     *bucket
+		score float64
+	}
+	probes := make([]probe, 0, len(b.sub))
+	for _, chain := range b.sub {
+		for _, sb := range chain {
+			// squared distance from `p` to the slot of sub bucket
+			score := 0.0
+			for j, v := range sb.values {
+				d := 0.0
+				if pos := positions[j]; pos < float64(v) {
+					d = float64(v) - pos
+				} else if pos >= float64(v+1) {
+					d = pos - float64(v+1)
+				}
+				score += d * d
+			}
+			probes = append(probes, probe{b: sb, score: score})
+		}
+	}
+	sort.Slice(probes, func(a, b int) bool { return probes[a].score < probes[b].score })
+	for _, pr := range probes {
+		if pr.b.sub != nil {
+			if !index.probeSplit(pr.b, p, i, depth+1, fn) {
+				return false
+			}
+			continue
+		}
+		for _, h := range pr.b.handles {
+			if !fn(h) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func equalValues(v1, v2 []int) bool {
+	if len(v1) != len(v2) {
+		return false
+	}
+	for i := range v1 {
+		if v1[i] != v2[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// clone returns copy of the LSH which hash tables could be
+// modified without affecting the original. The hash params &
+// the worker pool are shared, pointOf must be set again by the
+// owner of the clone.
+func (index *basicLsh) clone() *basicLsh {
+	c := *index
+	c.tables = make([]hashTable, len(index.tables))
+	for i, table := range index.tables {
+		c.tables[i] = cloneTable(table)
+	}
+	return &c
+}
+
+func cloneTable(table hashTable) hashTable {
+	c := make(hashTable, len(table))
+	for key, chain := range table {
+		clonedChain := make([]*bucket, len(chain))
+		for j, b := range chain {
+			clonedChain[j] = &bucket{
+				values:  b.values,
+				handles: append([]uint32(nil), b.handles...),
+				subW:    b.subW,
+			}
+			if b.sub != nil {
+				clonedChain[j].sub = cloneTable(b.sub)
+			}
+		}
+		c[key] = clonedChain
+	}
+	return c
+}
+
+// insert adds a new data point to the LSH. hvs is the hash
+// values of the point, h is the handle of the data point.
+func (index *basicLsh) insert(hvs hashValues, h uint32) {
+	// Insert handle into all hash tables
+	index.pool.forEach(len(index.tables), func(i int) {
+		table := index.tables[i]
+		key, values := hvs.keys[i], hvs.tableValues(i, index.m)
+		w := 0.0
+		for depth := 0; ; depth++ {
+			if depth > 0 {
+				values, key = index.splitHash(hvs.point, i, depth, w)
+			}
+			b := lookup(table, key, values)
+			if b == nil {
+				b = &bucket{values: append([]int(nil), values...)}
+				table[key] = append(table[key], b)
+				atomic.AddInt64(&index.numBuckets, 1)
+			}
+			// descend into split bucket
+			if b.sub != nil {
+				table, w = b.sub, b.subW
+				continue
+			}
+			b.handles = append(b.handles, h)
+			// the bucket might not be split when all of its points are
+			// identical, so only retry every maxBucketSize inserts
+			if index.maxBucketSize > 0 && len(b.handles)%(index.maxBucketSize+1) == 0 && depth < maxSplitDepth {
+				index.split(b, i, depth+1)
+			}
+			return
+		}
+	})
+}
+
+// split moves the handles of bucket `b` in table i into its sub
+// table, `depth` is the depth of the sub table.
+func (index *basicLsh) split(b *bucket, i, depth int) {
+	points := make([]point, len(b.handles))
+	for k, h := range b.handles {
+		points[k] = index.pointOf(h)
+	}
+	// use average standard deviation of the projections as slot
+	// size, so each extra hyperplane divides the points into few
+	// slots with none of them holding most of the points
+	w := 0.0
+	for j := 0; j < index.splitM; j++ {
+		hj := index.splitHyperplane(depth, j)
+		dots := make([]float64, len(points))
+		mean := 0.0
+		for k, p := range points {
+			dots[k] = index.project(p, i, hj)
+			mean += dots[k] / float64(len(points))
+		}
+		variance := 0.0
+		for _, dot := range dots {
+			variance += (dot - mean) * (dot - mean) / float64(len(points))
+		}
+		w += math.Sqrt(variance)
+	}
+	w /= float64(index.splitM)
+	// the points are practically identical, splitting them
+	// would only create sub table with single bucket
+	if w < index.w*1e-9 {
+		return
+	}
+	b.sub, b.subW = make(hashTable), w
+	for k, h := range b.handles {
+		values, key := index.splitHash(points[k], i, depth, w)
+		sb := lookup(b.sub, key, values)
+		if sb == nil {
+			sb = &bucket{values: values}
+			b.sub[key] = append(b.sub[key], sb)
+			atomic.AddInt64(&index.numBuckets, 1)
+		}
+		sb.handles = append(sb.handles, h)
+	}
+	b.handles = nil
+}
+
+// query finds the handles of approximate nearest neighbour
+// candidates, in un-sorted order, given the hash values of query
+// point. The handles found are marked in `seen`, so the same
+// handle is returned only once.
+func (index *basicLsh) query(hvs hashValues, seen bitset) []uint32 {
+	handles := make([]uint32, 0)
+	for i := range index.tables {
+		b := lookup(index.tables[i], hvs.keys[i], hvs.tableValues(i, index.m))
+		if b == nil {
+			continue
+		}
+		if b.sub == nil {
+			for _, h := range b.handles {
+				if seen.has(h) {
+					continue
+				}
+				seen.set(h)
+				handles = append(handles, h)
+			}
+			continue
+		}
+		// probe the nearest sub buckets of split bucket until
+		// the number of probed handles reaches the bound
+		numProbed := 0
+		index.probeSplit(b, hvs.point, i, 1, func(h uint32) bool {
+			if !seen.has(h) {
+				seen.set(h)
+				handles = append(handles, h)
+			}
+			numProbed++
+			return numProbed < index.splitProbeSize()
+		})
+	}
+	return handles
+}
+
+// splitProbeSize returns the number of handles probed in the sub
+// tables of split bucket for single query.
+func (index *basicLsh) splitProbeSize() int {
+	return splitProbeFactor * index.maxBucketSize
+}
+
+// delete removes a data point from the LSH. keys are the keys of
+// the point in the top level tables which are taken from its hash
+// values on insert, h is the handle of the data point. The handle is
+// searched in the sub tables of split buckets instead of re-hashing
+// the point, so it is removed even when the point has changed.
+func (index *basicLsh) delete(keys []uint64, h uint32) {
+	// Delete handle from all hash tables
+	index.pool.forEach(len(index.tables), func(i int) {
+		table := index.tables[i]
+		for _, b := range table[keys[i]] {
+			if !index.deleteFromBucket(b, h) {
+				continue
+			}
+			if len(b.handles) == 0 && len(b.sub) == 0 {
+				removeBucket(table, keys[i], b)
+				atomic.AddInt64(&index.numBuckets, -1)
+			}
+			return
+		}
+	})
+}
+
+// deleteFromBucket removes handle `h` from bucket `b` or from the
+// buckets in its sub table, it returns false when `h` is not found.
+// The buckets which become empty are removed from the sub table.
+func (index *basicLsh) deleteFromBucket(b *bucket, h uint32) bool {
+	if b.sub == nil {
+		for j := range b.handles {
+			if b.handles[j] == h {
+				b.handles[j] = b.handles[len(b.handles)-1]
+				b.handles = b.handles[:len(b.handles)-1]
+				return true
+			}
+		}
+		return false
+	}
+	for key, chain := range b.sub {
+		for _, sb := range chain {
+			if !index.deleteFromBucket(sb, h) {
+				continue
+			}
+			if len(sb.handles) == 0 && len(sb.sub) == 0 {
+				removeBucket(b.sub, key, sb)
+				atomic.AddInt64(&index.numBuckets, -1)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// deleteAll removes all handles marked in `removed` from the LSH
+// by scanning every bucket once.
+func (index *basicLsh) deleteAll(removed bitset) {
+	index.pool.forEach(len(index.tables), func(i int) {
+		index.deleteFromTable(index.tables[i], removed)
+	})
+}
+
+func (index *basicLsh) deleteFromTable(table hashTable, removed bitset) {
+	for key, chain := range table {
+		remaining := chain[:0]
+		for _, b := range chain {
+			if b.sub != nil {
+				index.deleteFromTable(b.sub, removed)
+			}
+			handles := b.handles[:0]
+			for _, h := range b.handles {
+				if !removed.has(h) {
+					handles = append(handles, h)
+				}
+			}
+			b.handles = handles
+			// remove empty bucket from the chain
+			if len(b.handles) == 0 && len(b.sub) == 0 {
+				atomic.AddInt64(&index.numBuckets, -1)
+				continue
+			}
+			remaining = append(remaining, b)
+		}
+		if len(remaining) == 0 {
+			delete(table, key)
+		} else {
+			table[key] = remaining
+		}
+	}
+}
+
+// reset removes all data points from the LSH
+func (index *basicLsh) reset() {
+	for i := range index.tables {
+		index.tables[i] = make(hashTable)
+	}
+	atomic.StoreInt64(&index.numBuckets, 0)
+}
+
+// removeBucket removes bucket `b` from the chain of `key` in `table`
+func removeBucket(table hashTable, key uint64, b *bucket) {
+	chain := table[key]
+	for j := range chain {
+		if chain[j] == b {
+			chain[j] = chain[len(chain)-1]
+			chain = chain[:len(chain)-1]
+			break
+		}
+	}
+	if len(chain) == 0 {
+		delete(table, key)
+	} else {
+		table[key] = chain
+	}
+}
+
+// walkBuckets calls `fn` for all buckets in `table` including the
+// buckets in sub tables of split buckets. The depth of buckets in
+// `table` is `depth`.
+func walkBuckets(table hashTable, depth int, fn func(b *bucket, depth int)) {
+	for _, chain := range table {
+		for _, b := range chain {
+			fn(b, depth)
+			if b.sub != nil {
+				walkBuckets(b.sub, depth+1, fn)
+			}
+		}
+	}
+}
+
+// bitset is used for marking handles
+type bitset []uint64
+
+// newBitset returns bitset which could hold handles in [0, n)
+func newBitset(n int) bitset {
+	return make(bitset, (n+63)/64)
+}
+
+func (s bitset) has(h uint32) bool { return s[h/64]&(1<<(h%64)) != 0 }
+
+func (s bitset) set(h uint32) { s[h/64] |= 1 << (h % 64) }
+
+// bitsetPool is used for reusing bitsets between queries, so
+// large index doesn't allocate big bitset on every query.
+var bitsetPool = sync.Pool{
+	New: func() interface{} { return new(bitset) },
+}
+
+// acquireBitset returns empty bitset which could hold handles
+// in [0, n) from the pool.
+func acquireBitset(n int) *bitset {
+	s := bitsetPool.Get().(*bitset)
+	if len(*s) < (n+63)/64 {
+		*s = newBitset(n)
+	}
+	return s
+}
+
+// releaseBitset clears `handles` from `s` then puts it back
+// to the pool.
+func releaseBitset(s *bitset, handles []uint32) {
+	for _, h := range handles {
+		(*s)[h/64] = 0
+	}
+	bitsetPool.Put(s)
+}
@@ -0,0 +1,350 @@
+package knn
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// SetDocument represents single entry in MinHashKNN index,
+// for example set of token shingles of a text.
+type SetDocument interface {
+	GetID() string
+	GetSet() []string
+}
+
+// SetResultDocument is wrapper for SetDocument but with
+// extra information related to search result
+type SetResultDocument struct {
+	Document   SetDocument
+	Similarity float64 // jaccard similarity, the higher the better
+}
+
+// MinHasher generates MinHash signatures of sets. Two signatures
+// generated by the same MinHasher could be used to estimate jaccard
+// similarity of their sets.
+type MinHasher struct {
+	// params of each hash function
+	a []uint64
+	b []uint64
+}
+
+// NewMinHasher returns MinHasher which generates signature
+// with `numHash` values. MinHashers with the same `numHash` &
+// `seed` generate the same signatures.
+func NewMinHasher(numHash int, seed int64) *MinHasher {
+	random := rand.New(rand.NewSource(seed))
+	a := make([]uint64, numHash)
+	b := make([]uint64, numHash)
+	for i := 0; i < numHash; i++ {
+		// multiplier must be odd so the function is a permutation
+		a[i] = random.Uint64() | 1
+		b[i] = random.Uint64()
+	}
+	return &MinHasher{a: a, b: b}
+}
+
+// Signature returns MinHash signature of `set`
+func (h *MinHasher) Signature(set []string) []uint64 {
+	sig := make([]uint64, len(h.a))
+	for i := range sig {
+		sig[i] = math.MaxUint64
+	}
+	hasher := fnv.New64a()
+	for _, elem := range set {
+		hasher.Reset()
+		hasher.Write([]byte(elem))
+		x := hasher.Sum64()
+		for i := range sig {
+			v := mix64(x*h.a[i] + h.b[i])
+			if v < sig[i] {
+				sig[i] = v
+			}
+		}
+	}
+	return sig
+}
+
+// EstimateSimilarity returns estimated jaccard similarity of two
+// sets from their signatures. Input `sig1` & `sig2` assummed has
+// same length.
+func EstimateSimilarity(sig1, sig2 []uint64) float64 {
+	if len(sig1) == 0 {
+		return 0
+	}
+	numEqual := 0
+	for i := range sig1 {
+		if sig1[i] == sig2[i] {
+			numEqual++
+		}
+	}
+	return float64(numEqual) / float64(len(sig1))
+}
+
+// mix64 is the finalizer of splitmix64, it is used for
+// scattering the bits of hash value
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// MinHashKNN is the index for searching similar sets by jaccard
+// similarity. It is based on banded MinHash LSH index.
+type MinHashKNN struct {
+	// hasher is used for generating signature of the sets
+	hasher *MinHasher
+
+	// Signature is divided into bands, each band has its own
+	// hash table. Documents which have identical rows in at
+	// least one band become candidates.
+	numRows int
+	tables  []map[uint64][]string
+
+	// docMap stores minHashEntry of each document, we keep
+	// the band keys so delete doesn't need to scan all tables
+	docMap sync.Map
+
+	// The hash tables use normal map, so mutex is needed to
+	// prevent panic from concurrent read & write.
+	mux sync.RWMutex
+}
+
+type minHashEntry struct {
+	doc  SetDocument
+	keys []uint64
+}
+
+// NewMinHashKNN returns new initialized instance of MinHashKNN index.
+// It returns error when the configs are invalid.
+func NewMinHashKNN(configs MinHashConfigs) (*MinHashKNN, error) {
+	// check input validity
+	if configs.NumBand <= 0 || configs.NumRow <= 0 {
+		return nil, fmt.Errorf("NumBand & NumRow must be greater than 0")
+	}
+	tables := make([]map[uint64][]string, configs.NumBand)
+	for i := range tables {
+		tables[i] = make(map[uint64][]string)
+	}
+	return &MinHashKNN{
+		hasher:  NewMinHasher(configs.NumBand*configs.NumRow, 1),
+		numRows: configs.NumRow,
+		tables:  tables,
+		docMap:  sync.Map{},
+	}, nil
+}
+
+// Add is used for introduce new document to index. If document
+// with the same id already exists, it will be replaced.
+func (n *MinHashKNN) Add(doc SetDocument) error {
+	// check input validity
+	if doc == nil || len(doc.GetID()) == 0 || len(doc.GetSet()) == 0 {
+		return fmt.Errorf("%w: trying to insert bad document", ErrInvalidDocument)
+	}
+	keys := n.bandKeys(n.hasher.Signature(doc.GetSet()))
+	// acquire lock
+	n.mux.Lock()
+	// defer unlock
+	defer n.mux.Unlock()
+
+	// remove old document from hash tables
+	if v, ok := n.docMap.Load(doc.GetID()); ok {
+		n.deleteKeys(doc.GetID(), v.(*minHashEntry).keys)
+	}
+	// insert document to hash tables
+	for i, key := range keys {
+		n.tables[i][key] = append(n.tables[i][key], doc.GetID())
+	}
+	// insert document to map
+	n.docMap.Store(doc.GetID(), &minHashEntry{doc: doc, keys: keys})
+
+	return nil
+}
+
+// Query returns maximum `k` similar documents. The result
+// already sorted from most similar to least similar documents.
+func (n *MinHashKNN) Query(set []string, k int) ([]SetResultDocument, error) {
+	// check input validity
+	if k <= 0 {
+		return nil, ErrInvalidK
+	}
+	resultDocs, err := n.query(set)
+	if err != nil {
+		return nil, err
+	}
+	// cut the result into max k documents
+	if len(resultDocs) > k {
+		resultDocs = resultDocs[:k]
+	}
+	return resultDocs, nil
+}
+
+// QueryThreshold returns all documents which jaccard similarity
+// is at least `threshold`. The result already sorted from most
+// similar to least similar documents.
+func (n *MinHashKNN) QueryThreshold(set []string, threshold float64) ([]SetResultDocument, error) {
+	// check input validity
+	if threshold < 0 || threshold > 1 {
+		return nil, fmt.Errorf("value of threshold must be between 0 and 1")
+	}
+	resultDocs, err := n.query(set)
+	if err != nil {
+		return nil, err
+	}
+	// cut the result on the first document below threshold
+	for i := range resultDocs {
+		if resultDocs[i].Similarity < threshold {
+			return resultDocs[:i], nil
+		}
+	}
+	return resultDocs, nil
+}
+
+// query returns all candidates of `set` sorted by their
+// jaccard similarity
+func (n *MinHashKNN) query(set []string) ([]SetResultDocument, error) {
+	// check input validity
+	if len(set) == 0 {
+		return nil, fmt.Errorf("%w: set must not empty", ErrEmptyVector)
+	}
+	keys := n.bandKeys(n.hasher.Signature(set))
+	elems := make(map[string]struct{}, len(set))
+	for _, elem := range set {
+		elems[elem] = struct{}{}
+	}
+	// acquire read lock
+	n.mux.RLock()
+	// defer read unlock
+	defer n.mux.RUnlock()
+
+	// get full document info of candidates including
+	// their exact similarity with input set
+	seen := make(map[string]bool)
+	resultDocs := make([]SetResultDocument, 0)
+	for i, key := range keys {
+		for _, id := range n.tables[i][key] {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			v, ok := n.docMap.Load(id)
+			if !ok {
+				continue
+			}
+			doc := v.(*minHashEntry).doc
+			resultDocs = append(resultDocs, SetResultDocument{
+				Document:   doc,
+				Similarity: calcJaccardSimilarity(elems, doc.GetSet()),
+			})
+		}
+	}
+	// sort by similarity from maximum to minimum
+	sort.Slice(resultDocs, func(i int, j int) bool {
+		return resultDocs[i].Similarity > resultDocs[j].Similarity
+	})
+	return resultDocs, nil
+}
+
+// calcJaccardSimilarity returns jaccard similarity between
+// `elems` & `set`
+func calcJaccardSimilarity(elems map[string]struct{}, set []string) float64 {
+	seen := make(map[string]struct{}, len(set))
+	intersection := 0
+	for _, elem := range set {
+		if _, ok := seen[elem]; ok {
+			continue
+		}
+		seen[elem] = struct{}{}
+		if _, ok := elems[elem]; ok {
+			intersection++
+		}
+	}
+	union := len(elems) + len(seen) - intersection
+	if union == 0 {
+		return 0
+	}
+	return float64(intersection) / float64(union)
+}
+
+// Delete is used to delete appointed document from index.
+// The document will literally deleted from memory. It returns
+// ErrNotFound when the document is not in the index.
+func (n *MinHashKNN) Delete(docID string) error {
+	// check input validity
+	if len(docID) == 0 {
+		return fmt.Errorf("%w: document id must not empty", ErrInvalidDocument)
+	}
+	// acquire lock
+	n.mux.Lock()
+	// defer unlock
+	defer n.mux.Unlock()
+
+	v, ok := n.docMap.Load(docID)
+	if !ok {
+		return ErrNotFound
+	}
+	// delete from hash tables
+	n.deleteKeys(docID, v.(*minHashEntry).keys)
+	// delete document from map
+	n.docMap.Delete(docID)
+
+	return nil
+}
+
+// Get is used to get single document from index.
+// If document not found returns ErrNotFound.
+func (n *MinHashKNN) Get(docID string) (SetDocument, error) {
+	// check input validity
+	if len(docID) == 0 {
+		return nil, fmt.Errorf("%w: document id must not empty", ErrInvalidDocument)
+	}
+	// load document from doc map
+	v, ok := n.docMap.Load(docID)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return v.(*minHashEntry).doc, nil
+}
+
+// bandKeys returns hash table key of each band in `sig`
+func (n *MinHashKNN) bandKeys(sig []uint64) []uint64 {
+	keys := make([]uint64, len(n.tables))
+	buf := make([]byte, 8)
+	hasher := fnv.New64a()
+	for i := range keys {
+		hasher.Reset()
+		for _, v := range sig[i*n.numRows : (i+1)*n.numRows] {
+			binary.LittleEndian.PutUint64(buf, v)
+			hasher.Write(buf)
+		}
+		keys[i] = hasher.Sum64()
+	}
+	return keys
+}
+
+// deleteKeys removes `id` from the buckets of `keys`
+func (n *MinHashKNN) deleteKeys(id string, keys []uint64) {
+	for i, key := range keys {
+		bucket := n.tables[i][key]
+		for j := range bucket {
+			if bucket[j] != id {
+				continue
+			}
+			bucket[j] = bucket[len(bucket)-1]
+			bucket = bucket[:len(bucket)-1]
+			break
+		}
+		if len(bucket) == 0 {
+			delete(n.tables[i], key)
+		} else {
+			n.tables[i][key] = bucket
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package knn
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// docRecord is the JSON form of single document, it is used for
+// persisting the index & in the replication stream. Dense document
+// has Vector while sparse document has Indices & Values.
+type docRecord struct {
+	ID        string                 `json:"id,omitempty"`
+	Vector    []float64              `json:"vector,omitempty"`
+	Indices   []int                  `json:"indices,omitempty"`
+	Values    []float64              `json:"values,omitempty"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	ExpiresAt int64                  `json:"expires_at,omitempty"`
+}
+
+// newDocRecord returns record of document in entry `e`, the metadata
+// is only kept when the document implements MetadataDocument
+func newDocRecord(e entry) docRecord {
+	r := docRecord{ExpiresAt: e.expiresAt}
+	var doc interface{}
+	switch d := e.doc.(type) {
+	case sparseDoc:
+		doc = d.SparseDocument
+		r.ID = d.GetID()
+		r.Indices, r.Values = d.GetSparseVector()
+	case Document:
+		doc = d
+		r.ID = d.GetID()
+		r.Vector = d.GetVector()
+	}
+	if m, ok := doc.(MetadataDocument); ok {
+		r.Metadata = m.GetMetadata()
+	}
+	return r
+}
+
+// add inserts the document of record into `n` as BasicDocument
+// or BasicSparseDocument
+func (r docRecord) add(n *KNN) error {
+	if r.Indices != nil {
+		return n.addSparse(&BasicSparseDocument{
+			ID:       r.ID,
+			Indices:  r.Indices,
+			Values:   r.Values,
+			Metadata: r.Metadata,
+		}, r.ExpiresAt)
+	}
+	return n.add(&BasicDocument{
+		ID:       r.ID,
+		Vector:   r.Vector,
+		Metadata: r.Metadata,
+	}, r.ExpiresAt)
+}
+
+// Save writes all documents in the index to `w` as JSON lines, so
+// they could be loaded back by Load. The expired documents are not
+// saved. Only the documents are saved, the hash tables are rebuilt
+// on Load using the configs of the loading index.
+func (n *KNN) Save(w io.Writer) error {
+	// collect entries, so the index is not locked
+	// while writing to `w`
+	state, release := n.readState()
+	now := time.Now().UnixNano()
+	entries := make([]entry, 0, len(state.handles))
+	for _, e := range state.entries {
+		if e.doc != nil && !e.expired(now) {
+			entries = append(entries, e)
+		}
+	}
+	release()
+
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	for _, e := range entries {
+		if err := enc.Encode(newDocRecord(e)); err != nil {
+			return fmt.Errorf("unable to save document due: %w", err)
+		}
+	}
+	return bw.Flush()
+}
+
+// Load adds documents saved by Save from `r` to the index, the
+// documents are reconstructed as BasicDocument or BasicSparseDocument.
+// The existing documents with the same ids are replaced & the expired
+// documents are skipped. It stops on the first invalid document, the
+// documents before it are already added.
+func (n *KNN) Load(r io.Reader) error {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	for i := 1; ; i++ {
+		var record docRecord
+		err := dec.Decode(&record)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("unable to read document %v due: %w", i, err)
+		}
+		if record.ExpiresAt > 0 && record.ExpiresAt <= time.Now().UnixNano() {
+			continue
+		}
+		if err := record.add(n); err != nil {
+			return fmt.Errorf("unable to load document %v due: %w", i, err)
+		}
+	}
+}
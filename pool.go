@@ -0,0 +1,55 @@
+package knn
+
+// workerPool executes tasks on fixed number of long-living
+// goroutines, so the engine doesn't need to spawn new goroutine
+// for every hash table on every write.
+type workerPool struct {
+	tasks chan func()
+}
+
+// newWorkerPool returns pool with `numWorkers` goroutines. It
+// returns nil when `numWorkers` is less than 2, nil pool executes
+// the tasks sequentially on the caller goroutine.
+func newWorkerPool(numWorkers int) *workerPool {
+	if numWorkers < 2 {
+		return nil
+	}
+	p := &workerPool{tasks: make(chan func())}
+	for i := 0; i < numWorkers; i++ {
+		go func() {
+			for task := range p.tasks {
+				task()
+			}
+		}()
+	}
+	return p
+}
+
+// forEach calls `fn` for every i in [0, n) & waits until all
+// of them are done.
+func (p *workerPool) forEach(n int, fn func(i int)) {
+	if p == nil {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+	done := make(chan struct{}, n)
+	for i := 0; i < n; i++ {
+		i := i
+		p.tasks <- func() {
+			fn(i)
+			done <- struct{}{}
+		}
+	}
+	for i := 0; i < n; i++ {
+		<-done
+	}
+}
+
+// close stops the goroutines of the pool
+func (p *workerPool) close() {
+	if p != nil {
+		close(p.tasks)
+	}
+}
@@ -0,0 +1,135 @@
+package knn
+
+import (
+	"fmt"
+	"math"
+)
+
+// ScalarQuantizer compresses vectors into int8 codes using
+// per-dimension min/max ranges. Each dimension is split into
+// 256 evenly sized steps, so a stored vector takes 1 byte per
+// dimension instead of 8.
+type ScalarQuantizer struct {
+	// min holds the lower bound of each dimension
+	min []float64
+	// step holds the width of single quantization step
+	// for each dimension
+	step []float64
+}
+
+// NewScalarQuantizer returns quantizer for given per-dimension
+// ranges. Values outside of the ranges will be clamped to the
+// nearest bound when encoded.
+func NewScalarQuantizer(min, max []float64) (*ScalarQuantizer, error) {
+	// check input validity
+	if len(min) == 0 {
+		return nil, fmt.Errorf("ranges must not empty")
+	}
+	if len(min) != len(max) {
+		return nil, fmt.Errorf("mismatch ranges dimension, min: %v, max: %v", len(min), len(max))
+	}
+	q := &ScalarQuantizer{
+		min:  make([]float64, len(min)),
+		step: make([]float64, len(min)),
+	}
+	for i := range min {
+		if max[i] < min[i] {
+			return nil, fmt.Errorf("max is lower than min on dimension %v", i)
+		}
+		q.min[i] = min[i]
+		q.step[i] = (max[i] - min[i]) / 255
+	}
+	return q, nil
+}
+
+// TrainScalarQuantizer returns quantizer which ranges are taken
+// from minimum & maximum value of each dimension in `vectors`.
+func TrainScalarQuantizer(vectors [][]float64) (*ScalarQuantizer, error) {
+	// check input validity
+	if len(vectors) == 0 || len(vectors[0]) == 0 {
+		return nil, fmt.Errorf("training vectors must not empty")
+	}
+	dim := len(vectors[0])
+	min := make([]float64, dim)
+	max := make([]float64, dim)
+	copy(min, vectors[0])
+	copy(max, vectors[0])
+	for _, vector := range vectors[1:] {
+		if len(vector) != dim {
+			return nil, &DimensionError{Expected: dim, Got: len(vector)}
+		}
+		for i, v := range vector {
+			min[i] = math.Min(min[i], v)
+			max[i] = math.Max(max[i], v)
+		}
+	}
+	return NewScalarQuantizer(min, max)
+}
+
+// Dimension returns number of dimension handled by quantizer
+func (q *ScalarQuantizer) Dimension() int {
+	return len(q.min)
+}
+
+// Encode returns int8 codes of `vector`. Input `vector` assummed
+// has same dimension with the quantizer.
+func (q *ScalarQuantizer) Encode(vector []float64) []int8 {
+	codes := make([]int8, len(q.min))
+	for i := range codes {
+		level := 0.0
+		if q.step[i] > 0 {
+			level = math.Round((vector[i] - q.min[i]) / q.step[i])
+		}
+		// clamp the level into available 256 levels
+		level = math.Max(0, math.Min(255, level))
+		codes[i] = int8(level - 128)
+	}
+	return codes
+}
+
+// Decode returns approximation of original vector from `codes`
+func (q *ScalarQuantizer) Decode(codes []int8) []float64 {
+	vector := make([]float64, len(codes))
+	for i, code := range codes {
+		vector[i] = q.min[i] + float64(int(code)+128)*q.step[i]
+	}
+	return vector
+}
+
+// squaredDistance returns approximate squared euclidean distance
+// between `codes` & `vector`. The codes are decoded on the fly so
+// the query vector keeps its full precision.
+func (q *ScalarQuantizer) squaredDistance(codes []int8, vector []float64) float64 {
+	sum := 0.0
+	for i, code := range codes {
+		d := q.min[i] + float64(int(code)+128)*q.step[i] - vector[i]
+		sum += d * d
+	}
+	return sum
+}
+
+// quantizedDoc is the document kept by the index when quantization
+// is enabled without rescoring. It only holds the codes of the vector,
+// the vector is decoded when it is needed.
+type quantizedDoc struct {
+	id        string
+	codes     []int8
+	metadata  map[string]interface{}
+	quantizer *ScalarQuantizer
+}
+
+// newQuantizedDoc returns quantizedDoc of `doc` which vector is
+// encoded into `codes`, the metadata of `doc` is kept
+func newQuantizedDoc(doc Document, codes []int8, quantizer *ScalarQuantizer) *quantizedDoc {
+	d := &quantizedDoc{id: doc.GetID(), codes: codes, quantizer: quantizer}
+	if m, ok := doc.(MetadataDocument); ok {
+		d.metadata = m.GetMetadata()
+	}
+	return d
+}
+
+func (d *quantizedDoc) GetID() string { return d.id }
+
+func (d *quantizedDoc) GetVector() []float64 { return d.quantizer.Decode(d.codes) }
+
+func (d *quantizedDoc) GetMetadata() map[string]interface{} { return d.metadata }
@@ -0,0 +1,159 @@
+package knn
+
+import "fmt"
+
+// reconfigureBatchSize is the number of documents inserted into the
+// new hash tables on every lock acquisition, so the writes & queries
+// could still go through while the tables are rebuilt.
+const reconfigureBatchSize = 256
+
+// rebuild holds the LSH index which is being built by Reconfigure
+type rebuild struct {
+	lsh *basicLsh
+
+	// keys holds the keys of the buckets in lsh for every inserted
+	// handle, nil keys means the handle is not inserted yet. So the
+	// documents added by the writes during the rebuild are not
+	// inserted twice.
+	keys [][]uint64
+}
+
+// insert adds document in entry `e` with handle `h` into the new
+// LSH index, it does nothing when there is no rebuild.
+func (r *rebuild) insert(e entry, h uint32) {
+	if r == nil {
+		return
+	}
+	hvs := hashOf(r.lsh, e)
+	r.lsh.insert(hvs, h)
+	for len(r.keys) <= int(h) {
+		r.keys = append(r.keys, nil)
+	}
+	r.keys[h] = hvs.keys
+}
+
+// delete removes document with handle `h` from the new LSH index,
+// it does nothing when there is no rebuild or the document is not
+// inserted yet.
+func (r *rebuild) delete(h uint32) {
+	if r == nil || !r.isIndexed(h) {
+		return
+	}
+	r.lsh.delete(r.keys[h], h)
+	r.keys[h] = nil
+}
+
+func (r *rebuild) isIndexed(h uint32) bool {
+	return int(h) < len(r.keys) && r.keys[h] != nil
+}
+
+// Reconfigure rebuilds the hash tables of the index using the LSH
+// params in `configs`, which are NumHashTable, NumHyperplane, SlotSize,
+// SparseProjection, NumWorkers, MaxBucketSize & NumSplitHyperplane.
+// The other configs are ignored, VectorDimension must be the same as
+// the current one.
+//
+// The new tables are built from the indexed documents in small batches,
+// so the index could still be used meanwhile. Writes during the rebuild
+// are applied to both the current & the new tables, once the rebuild
+// is complete the new tables are swapped in atomically. Notice that the
+// index holds both tables during the rebuild, so its memory usage might
+// go beyond MemoryLimit temporarily.
+//
+// Reconfigure blocks until the new tables are swapped in. `progress` is
+// optional, it is called after every batch with the number of processed
+// document slots & the total slots.
+func (n *KNN) Reconfigure(configs Configs, progress func(done, total int)) error {
+	// check input validity
+	if configs.VectorDimension != n.vectorDimension {
+		return &DimensionError{Expected: n.vectorDimension, Got: configs.VectorDimension}
+	}
+	if err := configs.validateLsh(); err != nil {
+		return err
+	}
+	// acquire lock
+	n.mux.Lock()
+	if n.isClosed() {
+		n.mux.Unlock()
+		return fmt.Errorf("index is already closed")
+	}
+	if n.state.rebuild != nil {
+		n.mux.Unlock()
+		return fmt.Errorf("index is already being reconfigured")
+	}
+	r := &rebuild{lsh: newLshFromConfigs(configs, n.state.pointOf)}
+	n.state.rebuild = r
+	// documents added after this point are
+	// inserted into new tables by the writes
+	total := len(n.state.entries)
+	n.mux.Unlock()
+
+	for done := 0; done < total; {
+		end := done + reconfigureBatchSize
+		if end > total {
+			end = total
+		}
+		// acquire lock
+		n.mux.Lock()
+		if n.isClosed() {
+			n.abortRebuild()
+			n.mux.Unlock()
+			return fmt.Errorf("index is closed while reconfiguring")
+		}
+		// the entries might be shrunk by Reset
+		for h := done; h < end && h < len(n.state.entries); h++ {
+			e := n.state.entries[h]
+			if e.doc == nil || r.isIndexed(uint32(h)) {
+				continue
+			}
+			r.insert(e, uint32(h))
+		}
+		n.mux.Unlock()
+
+		done = end
+		if progress != nil {
+			progress(done, total)
+		}
+	}
+
+	// acquire lock
+	n.mux.Lock()
+	// defer unlock
+	defer n.mux.Unlock()
+
+	if n.isClosed() {
+		n.abortRebuild()
+		return fmt.Errorf("index is closed while reconfiguring")
+	}
+	// swap the tables, the entries must hold the
+	// keys of their buckets in the new tables
+	for h := range n.state.entries {
+		if n.state.entries[h].doc != nil {
+			n.state.entries[h].keys = r.keys[h]
+		}
+	}
+	old := n.state.lsh
+	n.state.lsh = r.lsh
+	n.state.rebuild = nil
+	n.dirty = true
+	old.pool.close()
+
+	return nil
+}
+
+// abortRebuild drops the rebuild of the state, the caller must
+// hold the lock.
+func (n *KNN) abortRebuild() {
+	n.state.rebuild.lsh.pool.close()
+	n.state.rebuild = nil
+}
+
+// isClosed returns true when Close has been called
+func (n *KNN) isClosed() bool {
+	select {
+	case <-n.closeCh:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,375 @@
+package knn
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"sync"
+	"sync/atomic"
+)
+
+// The replication stream is sequence of JSON lines, each line is
+// single logRecord. When connected through ServeConn & Sync, the
+// follower starts the stream by sending handshake line containing
+// the sequence number of its last applied mutation.
+//
+// When the follower starts from offset 0, the leader sends snapshot
+// of its documents first. The snapshot starts with reset record, all
+// snapshot records have sequence number of the last mutation applied
+// to the snapshot, then the stream continues from the next mutation.
+
+// logRecord is single mutation in the replication stream, the
+// document is only set for OpAdd & OpUpsert
+type logRecord struct {
+	Seq      uint64 `json:"seq"`
+	Op       string `json:"op"`
+	Snapshot bool   `json:"snapshot,omitempty"`
+	docRecord
+}
+
+// handshake is sent by the follower to start the stream
+type handshake struct {
+	FromSeq uint64 `json:"from_seq"`
+}
+
+// newLogRecord returns record of `event`
+func newLogRecord(event ChangeEvent) logRecord {
+	r := logRecord{Seq: event.Seq, Op: event.Op.String()}
+	r.ID = event.ID
+	if event.Op != OpAdd && event.Op != OpUpsert {
+		return r
+	}
+	var expiresAt int64
+	if !event.ExpiresAt.IsZero() {
+		expiresAt = event.ExpiresAt.UnixNano()
+	}
+	if event.SparseDocument != nil {
+		r.docRecord = newDocRecord(entry{doc: sparseDoc{event.SparseDocument}, expiresAt: expiresAt})
+	} else {
+		r.docRecord = newDocRecord(entry{doc: event.Document, expiresAt: expiresAt})
+	}
+	return r
+}
+
+// Leader streams ordered mutation log of KNN index to followers.
+// It keeps the last mutations in memory, so the followers could
+// resume from their last applied mutation after reconnecting.
+type Leader struct {
+	index   *KNN
+	hook    *leaderHook
+	logSize int
+
+	// events holds the last mutations in order, lastSeq is the
+	// sequence number of the last mutation in the log
+	events  []ChangeEvent
+	lastSeq uint64
+	closed  bool
+
+	mux  sync.Mutex
+	cond *sync.Cond
+}
+
+// leaderHook appends the mutations of the index to the log
+type leaderHook struct {
+	leader *Leader
+}
+
+func (h *leaderHook) OnAdd(event ChangeEvent) { h.leader.append(event) }
+
+func (h *leaderHook) OnDelete(event ChangeEvent) { h.leader.append(event) }
+
+// NewLeader returns leader which streams mutations of `index`
+// applied after the leader is created. `logSize` is the number of
+// last mutations kept for the followers which resume, the follower
+// which is further behind needs to start over from offset 0. The
+// default value of `logSize` is 10000.
+func NewLeader(index *KNN, logSize int) *Leader {
+	if logSize <= 0 {
+		logSize = 10000
+	}
+	l := &Leader{index: index, logSize: logSize}
+	l.hook = &leaderHook{leader: l}
+	l.cond = sync.NewCond(&l.mux)
+	// register the hook while holding the lock, so there is no
+	// pending mutation which is not logged
+	index.mux.Lock()
+	l.lastSeq = index.seq
+	index.hooks = append(index.hooks, l.hook)
+	atomic.AddInt32(&index.numListeners, 1)
+	index.mux.Unlock()
+
+	return l
+}
+
+// append adds `event` to the log & wakes up the streams
+func (l *Leader) append(event ChangeEvent) {
+	// acquire lock
+	l.mux.Lock()
+	// defer unlock
+	defer l.mux.Unlock()
+
+	l.events = append(l.events, event)
+	l.lastSeq = event.Seq
+	// only trim the log once in a while, so the cost is amortized
+	if len(l.events) > 2*l.logSize {
+		l.events = append([]ChangeEvent(nil), l.events[len(l.events)-l.logSize:]...)
+	}
+	l.cond.Broadcast()
+}
+
+// Serve streams the mutations after `fromSeq` to `w` until writing
+// to `w` fails or the leader is closed. When `fromSeq` is 0, snapshot
+// of the index is sent first. It returns ErrLogTruncated when the
+// mutations after `fromSeq` are no longer in the log. Notice that the
+// failure of `w` is only detected on the next mutation, use ServeConn
+// for detecting the disconnected follower while the leader is idle.
+func (l *Leader) Serve(w io.Writer, fromSeq uint64) error {
+	return l.serve(w, fromSeq, nil)
+}
+
+// serve is like Serve but it also stops once `stop` is closed, the
+// condition of the leader must be broadcasted after closing `stop`.
+func (l *Leader) serve(w io.Writer, fromSeq uint64, stop <-chan struct{}) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	n := l.index
+	n.mux.RLock()
+	lastSeq := n.seq
+	n.mux.RUnlock()
+	if fromSeq > lastSeq {
+		return fmt.Errorf("offset is ahead of the leader, offset: %v, last: %v", fromSeq, lastSeq)
+	}
+	cursor := fromSeq
+	if cursor == 0 {
+		seq, err := l.writeSnapshot(enc)
+		if err != nil {
+			return err
+		}
+		cursor = seq
+	}
+	for {
+		if err := bw.Flush(); err != nil {
+			return err
+		}
+		// wait for the mutations after cursor, notice that the
+		// mutations in the snapshot might not be logged yet
+		l.mux.Lock()
+		for !l.closed && !isClosed(stop) && l.lastSeq <= cursor {
+			l.cond.Wait()
+		}
+		if l.closed || isClosed(stop) {
+			l.mux.Unlock()
+			return nil
+		}
+		firstSeq := l.lastSeq - uint64(len(l.events)) + 1
+		if cursor+1 < firstSeq {
+			l.mux.Unlock()
+			return ErrLogTruncated
+		}
+		events := append([]ChangeEvent(nil), l.events[cursor+1-firstSeq:]...)
+		l.mux.Unlock()
+
+		// stream the mutations
+		for _, event := range events {
+			if err := enc.Encode(newLogRecord(event)); err != nil {
+				return err
+			}
+		}
+		cursor = events[len(events)-1].Seq
+	}
+}
+
+// writeSnapshot writes all documents of the index into `enc`, it
+// returns the sequence number of the last mutation in the snapshot
+func (l *Leader) writeSnapshot(enc *json.Encoder) (uint64, error) {
+	n := l.index
+	// acquire read lock
+	n.mux.RLock()
+	seq := n.seq
+	records := make([]logRecord, 0, len(n.state.handles)+1)
+	records = append(records, logRecord{Seq: seq, Op: OpReset.String(), Snapshot: true})
+	for _, h := range n.state.handles {
+		record := logRecord{Seq: seq, Op: OpUpsert.String(), Snapshot: true}
+		record.docRecord = newDocRecord(n.state.entries[h])
+		records = append(records, record)
+	}
+	// read unlock
+	n.mux.RUnlock()
+
+	for _, record := range records {
+		if err := enc.Encode(record); err != nil {
+			return 0, err
+		}
+	}
+	return seq, nil
+}
+
+// ServeConn reads handshake from the follower on `conn` then
+// streams the mutations requested by the follower to it. The follower
+// never writes after the handshake, so `conn` is read in background
+// for detecting the disconnected follower without waiting for the
+// next mutation. The caller should close `conn` once it returns.
+func (l *Leader) ServeConn(conn io.ReadWriter) error {
+	var hs handshake
+	if err := json.NewDecoder(conn).Decode(&hs); err != nil {
+		return fmt.Errorf("unable to read handshake due: %w", err)
+	}
+	stop := make(chan struct{})
+	go func() {
+		// reading fails once the follower is disconnected
+		// or `conn` is closed
+		io.Copy(ioutil.Discard, conn)
+		close(stop)
+		l.mux.Lock()
+		l.cond.Broadcast()
+		l.mux.Unlock()
+	}()
+	return l.serve(conn, hs.FromSeq, stop)
+}
+
+// isClosed returns true when `ch` is closed, nil channel is
+// never closed
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+// Accept serves the followers connected to `ln` until accepting
+// new connection fails, each follower is served in its own goroutine.
+func (l *Leader) Accept(ln net.Listener) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			l.ServeConn(conn)
+		}()
+	}
+}
+
+// Close stops all streams & stops logging the mutations
+func (l *Leader) Close() {
+	n := l.index
+	n.mux.Lock()
+	for i, hook := range n.hooks {
+		if hook == Hook(l.hook) {
+			n.hooks = append(n.hooks[:i], n.hooks[i+1:]...)
+			atomic.AddInt32(&n.numListeners, -1)
+			break
+		}
+	}
+	n.mux.Unlock()
+
+	l.mux.Lock()
+	l.closed = true
+	l.cond.Broadcast()
+	l.mux.Unlock()
+}
+
+// Follower applies the mutation log streamed by Leader to its
+// index. The documents are reconstructed as BasicDocument or
+// BasicSparseDocument. The follower index should not be written
+// by anything else, otherwise it would drift from the leader.
+type Follower struct {
+	index *KNN
+	seq   uint64
+	mux   sync.Mutex
+}
+
+// NewFollower returns follower which applies the mutations to `index`
+func NewFollower(index *KNN) *Follower {
+	return &Follower{index: index}
+}
+
+// Seq returns sequence number of the last applied mutation, it
+// is the offset for resuming the stream.
+func (f *Follower) Seq() uint64 {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	return f.seq
+}
+
+// ResetOffset makes the next Sync starts over from offset 0, it is
+// needed when the leader returns ErrLogTruncated or the leader is
+// replaced by new one.
+func (f *Follower) ResetOffset() {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.seq = 0
+}
+
+// Sync sends handshake with the last applied mutation to the leader
+// on `conn`, then applies the stream until it ends.
+func (f *Follower) Sync(conn io.ReadWriter) error {
+	if err := json.NewEncoder(conn).Encode(handshake{FromSeq: f.Seq()}); err != nil {
+		return fmt.Errorf("unable to send handshake due: %w", err)
+	}
+	return f.Apply(conn)
+}
+
+// Apply applies the mutations read from `r` until the end of the
+// stream. It returns error when the stream is malformed or there
+// is missing mutation, the applied mutations are kept so the stream
+// could be resumed from Seq().
+func (f *Follower) Apply(r io.Reader) error {
+	dec := json.NewDecoder(r)
+	for {
+		var record logRecord
+		if err := dec.Decode(&record); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if err := f.applyRecord(record); err != nil {
+			return err
+		}
+	}
+}
+
+// applyRecord applies single mutation to the index
+func (f *Follower) applyRecord(record logRecord) error {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	op, ok := parseChangeOp(record.Op)
+	if !ok {
+		return fmt.Errorf("unknown operation: %v", record.Op)
+	}
+	// snapshot starts with reset, the other snapshot records
+	// has the same sequence number as the reset
+	expSeq := f.seq + 1
+	if record.Snapshot && op == OpReset {
+		expSeq = record.Seq
+	} else if record.Snapshot {
+		expSeq = f.seq
+	}
+	if record.Seq != expSeq {
+		return fmt.Errorf("unexpected sequence number, expected: %v, got: %v", expSeq, record.Seq)
+	}
+	n := f.index
+	switch op {
+	case OpAdd, OpUpsert:
+		if err := record.docRecord.add(n); err != nil {
+			return fmt.Errorf("unable to apply mutation %v due: %w", record.Seq, err)
+		}
+	case OpDelete, OpEvict, OpExpire:
+		if err := n.Delete(record.ID); err != nil && !errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("unable to apply mutation %v due: %w", record.Seq, err)
+		}
+	case OpReset:
+		n.Reset()
+	}
+	f.seq = record.Seq
+	return nil
+}
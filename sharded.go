@@ -0,0 +1,355 @@
+package knn
+
+import (
+	"container/heap"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ShardedKNN is KNN index which documents are partitioned by their
+// id across multiple independent KNN shards. Each shard has its own
+// lock, so writing to one shard doesn't block queries on the others.
+//
+// Configs.Capacity & Configs.MemoryLimit are divided evenly across the
+// shards & enforced by each shard on its own. Since the documents are
+// not spread perfectly even, a shard might evict documents or return
+// ErrMemoryLimit before the whole index reaches the limit. Each shard
+// is limited to at least 1 document or byte, so the total capacity is
+// never less than the number of shards.
+type ShardedKNN struct {
+	shards   []*KNN
+	counters []*shardCounters
+}
+
+// shardCounters holds operation counters of single shard, the
+// fields are updated atomically.
+type shardCounters struct {
+	numAdds    uint64
+	numDeletes uint64
+	numQueries uint64
+}
+
+// ShardStats holds statistics of single shard in ShardedKNN
+type ShardStats struct {
+	NumDocuments int
+	NumAdds      uint64
+	NumDeletes   uint64
+	NumQueries   uint64
+}
+
+// NewShardedKNN returns new initialized instance of ShardedKNN
+// index with `numShards` shards. All shards use the same configs
+// except Capacity & MemoryLimit which are divided across them, so
+// the query result is the same as if the documents are stored in
+// single KNN. Just like NewKNN, the configs are not validated.
+func NewShardedKNN(numShards int, configs Configs) *ShardedKNN {
+	if numShards < 1 {
+		numShards = 1
+	}
+	shards := make([]*KNN, numShards)
+	counters := make([]*shardCounters, numShards)
+	for i := range shards {
+		shardConfigs := configs
+		shardConfigs.Capacity = int(shareOf(int64(configs.Capacity), numShards, i))
+		shardConfigs.MemoryLimit = shareOf(configs.MemoryLimit, numShards, i)
+		shards[i] = NewKNN(shardConfigs)
+		counters[i] = &shardCounters{}
+	}
+	return &ShardedKNN{
+		shards:   shards,
+		counters: counters,
+	}
+}
+
+// shareOf returns the share of shard `i` when `limit` is divided
+// across `numShards` shards, the remainder goes to the first shards.
+// The share is at least 1 since 0 means no limit.
+func shareOf(limit int64, numShards, i int) int64 {
+	if limit <= 0 {
+		return limit
+	}
+	share := limit / int64(numShards)
+	if int64(i) < limit%int64(numShards) {
+		share++
+	}
+	if share < 1 {
+		share = 1
+	}
+	return share
+}
+
+// shardOf returns index of the shard which holds `docID`
+func (n *ShardedKNN) shardOf(docID string) int {
+	hasher := fnv.New32a()
+	hasher.Write([]byte(docID))
+	return int(hasher.Sum32() % uint32(len(n.shards)))
+}
+
+// Add is used for introduce new document to index
+func (n *ShardedKNN) Add(doc Document) error {
+	// check input validity
+	if doc == nil {
+		return fmt.Errorf("%w: trying to insert bad document", ErrInvalidDocument)
+	}
+	i := n.shardOf(doc.GetID())
+	if err := n.shards[i].Add(doc); err != nil {
+		return err
+	}
+	atomic.AddUint64(&n.counters[i].numAdds, 1)
+	return nil
+}
+
+// AddWithTTL is like Add but the document is expired after `ttl`
+func (n *ShardedKNN) AddWithTTL(doc Document, ttl time.Duration) error {
+	// check input validity
+	if doc == nil {
+		return fmt.Errorf("%w: trying to insert bad document", ErrInvalidDocument)
+	}
+	i := n.shardOf(doc.GetID())
+	if err := n.shards[i].AddWithTTL(doc, ttl); err != nil {
+		return err
+	}
+	atomic.AddUint64(&n.counters[i].numAdds, 1)
+	return nil
+}
+
+// AddSparse is used for introduce new sparse document to index
+func (n *ShardedKNN) AddSparse(doc SparseDocument) error {
+	// check input validity
+	if doc == nil {
+		return fmt.Errorf("%w: trying to insert bad document", ErrInvalidDocument)
+	}
+	i := n.shardOf(doc.GetID())
+	if err := n.shards[i].AddSparse(doc); err != nil {
+		return err
+	}
+	atomic.AddUint64(&n.counters[i].numAdds, 1)
+	return nil
+}
+
+// Query returns maximum `k` similar documents from all shards.
+// The result already sorted from most similar to least similar
+// documents.
+func (n *ShardedKNN) Query(vector []float64, k int) ([]ResultDocument, error) {
+	return n.fanOut(k, func(shard *KNN) ([]ResultDocument, error) {
+		return shard.Query(vector, k)
+	})
+}
+
+// QuerySparse is like Query but the input vector is sparse vector
+// represented by `indices` of its non-zero `values`.
+func (n *ShardedKNN) QuerySparse(indices []int, values []float64, k int) ([]ResultDocument, error) {
+	return n.fanOut(k, func(shard *KNN) ([]ResultDocument, error) {
+		return shard.QuerySparse(indices, values, k)
+	})
+}
+
+// fanOut executes `query` on all shards in parallel then merges
+// their results into top `k` documents.
+func (n *ShardedKNN) fanOut(k int, query func(shard *KNN) ([]ResultDocument, error)) ([]ResultDocument, error) {
+	results := make([][]ResultDocument, len(n.shards))
+	errs := make([]error, len(n.shards))
+	var wg sync.WaitGroup
+	wg.Add(len(n.shards))
+	for i := range n.shards {
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = query(n.shards[i])
+			atomic.AddUint64(&n.counters[i].numQueries, 1)
+		}(i)
+	}
+	wg.Wait()
+	// all shards validate input the same way, so just
+	// return the first error
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return mergeResultDocs(results, k), nil
+}
+
+// Delete is used to delete appointed document from index.
+// The document will literally deleted from memory. It returns
+// ErrNotFound when the document is not in the index.
+func (n *ShardedKNN) Delete(docID string) error {
+	i := n.shardOf(docID)
+	if err := n.shards[i].Delete(docID); err != nil {
+		return err
+	}
+	atomic.AddUint64(&n.counters[i].numDeletes, 1)
+	return nil
+}
+
+// DeleteWhere deletes all dense documents which `match` returns
+// true from all shards, it returns the number of deleted documents.
+// The shards are processed one by one.
+func (n *ShardedKNN) DeleteWhere(match func(doc Document) bool) (int, error) {
+	total := 0
+	for i, shard := range n.shards {
+		count, err := shard.DeleteWhere(match)
+		if err != nil {
+			return total, err
+		}
+		atomic.AddUint64(&n.counters[i].numDeletes, uint64(count))
+		total += count
+	}
+	return total, nil
+}
+
+// DeleteExpired deletes all expired documents from all shards,
+// it returns the number of deleted documents.
+func (n *ShardedKNN) DeleteExpired() int {
+	total := 0
+	for i, shard := range n.shards {
+		count := shard.DeleteExpired()
+		atomic.AddUint64(&n.counters[i].numDeletes, uint64(count))
+		total += count
+	}
+	return total
+}
+
+// Reset deletes all documents from all shards
+func (n *ShardedKNN) Reset() {
+	for _, shard := range n.shards {
+		shard.Reset()
+	}
+}
+
+// Get is used to get single document from index.
+// If document not found returns ErrNotFound.
+func (n *ShardedKNN) Get(docID string) (Document, error) {
+	return n.shards[n.shardOf(docID)].Get(docID)
+}
+
+// GetSparse is used to get single sparse document from index.
+// If document not found returns ErrNotFound.
+func (n *ShardedKNN) GetSparse(docID string) (SparseDocument, error) {
+	return n.shards[n.shardOf(docID)].GetSparse(docID)
+}
+
+// Commit publishes all writes since the last commit to readers
+// of every shard. Notice that the shards are committed one by one,
+// so readers might observe some shards committed before the others.
+func (n *ShardedKNN) Commit() {
+	for _, shard := range n.shards {
+		shard.Commit()
+	}
+}
+
+// Close stops background goroutines of all shards
+func (n *ShardedKNN) Close() {
+	for _, shard := range n.shards {
+		shard.Close()
+	}
+}
+
+// Len returns number of documents in all shards
+func (n *ShardedKNN) Len() int {
+	length := 0
+	for _, shard := range n.shards {
+		length += shard.Len()
+	}
+	return length
+}
+
+// Range calls `fn` for every dense document in all shards until
+// `fn` returns false. The shards are visited one by one, so `fn`
+// must not write to the shard being visited.
+func (n *ShardedKNN) Range(fn func(doc Document) bool) {
+	stopped := false
+	for _, shard := range n.shards {
+		shard.Range(func(doc Document) bool {
+			stopped = !fn(doc)
+			return !stopped
+		})
+		if stopped {
+			return
+		}
+	}
+}
+
+// IDs returns maximum `limit` ids of documents in all shards which
+// are greater than `cursor`, sorted in ascending order. Check
+// KNN.IDs for details.
+func (n *ShardedKNN) IDs(cursor string, limit int) ([]string, error) {
+	h := make(idHeap, 0)
+	for _, shard := range n.shards {
+		shardIDs, err := shard.IDs(cursor, limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range shardIDs {
+			h.pushLimit(id, limit)
+		}
+	}
+	return h.sorted(), nil
+}
+
+// ShardStats returns statistics of each shard
+func (n *ShardedKNN) ShardStats() []ShardStats {
+	stats := make([]ShardStats, len(n.shards))
+	for i, shard := range n.shards {
+		shard.mux.RLock()
+		numDocuments := len(shard.state.handles)
+		shard.mux.RUnlock()
+		stats[i] = ShardStats{
+			NumDocuments: numDocuments,
+			NumAdds:      atomic.LoadUint64(&n.counters[i].numAdds),
+			NumDeletes:   atomic.LoadUint64(&n.counters[i].numDeletes),
+			NumQueries:   atomic.LoadUint64(&n.counters[i].numQueries),
+		}
+	}
+	return stats
+}
+
+// mergeResultDocs merges sorted `results` into top `k` documents
+// using min-heap on the head of each result.
+func mergeResultDocs(results [][]ResultDocument, k int) []ResultDocument {
+	h := make(resultHeap, 0, len(results))
+	total := 0
+	for _, result := range results {
+		if len(result) > 0 {
+			h = append(h, result)
+			total += len(result)
+		}
+	}
+	heap.Init(&h)
+	// `k` might be much larger than the number of documents
+	if k > total {
+		k = total
+	}
+	merged := make([]ResultDocument, 0, k)
+	for len(merged) < k && h.Len() > 0 {
+		merged = append(merged, h[0][0])
+		if len(h[0]) > 1 {
+			h[0] = h[0][1:]
+			heap.Fix(&h, 0)
+		} else {
+			heap.Pop(&h)
+		}
+	}
+	return merged
+}
+
+// resultHeap is min-heap of sorted results ordered by
+// distance of their first document
+type resultHeap [][]ResultDocument
+
+func (h resultHeap) Len() int { return len(h) }
+
+func (h resultHeap) Less(i, j int) bool { return h[i][0].Distance < h[j][0].Distance }
+
+func (h resultHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *resultHeap) Push(x interface{}) { *h = append(*h, x.([]ResultDocument)) }
+
+func (h *resultHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
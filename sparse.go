@@ -0,0 +1,153 @@
+package knn
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// sparseDoc wraps SparseDocument stored in the index, so it won't be
+// mistaken as dense Document even when the underlying type
+// implements both interfaces.
+type sparseDoc struct {
+	SparseDocument
+}
+
+// AddSparse is used for introduce new sparse document to index.
+// The sparse document coexists with dense documents, so it could
+// be returned by both Query & QuerySparse.
+func (n *KNN) AddSparse(doc SparseDocument) error {
+	// check input validity
+	if doc == nil {
+		return fmt.Errorf("%w: trying to insert bad document", ErrInvalidDocument)
+	}
+	return n.addSparse(doc, expiryOf(doc))
+}
+
+// addSparse inserts `doc` which expires at `expiresAt` to index,
+// the existing document with the same id is replaced
+func (n *KNN) addSparse(doc SparseDocument, expiresAt int64) error {
+	// check input validity
+	if len(doc.GetID()) == 0 {
+		return fmt.Errorf("%w: trying to insert bad document", ErrInvalidDocument)
+	}
+	indices, values := doc.GetSparseVector()
+	if err := n.validateSparseVector(indices, values); err != nil {
+		return err
+	}
+	return n.insert(doc.GetID(), entry{doc: sparseDoc{doc}, expiresAt: expiresAt})
+}
+
+// QuerySparse is like Query but the input vector is sparse vector
+// represented by `indices` of its non-zero `values`.
+func (n *KNN) QuerySparse(indices []int, values []float64, k int) ([]ResultDocument, error) {
+	// check input validity
+	if err := n.validateSparseVector(indices, values); err != nil {
+		return nil, err
+	}
+	if k <= 0 {
+		return nil, ErrInvalidK
+	}
+	// acquire state for reading
+	state, release := n.readState()
+	// defer release
+	defer release()
+
+	// get handles of similar documents
+	handles := state.query(state.lsh.hashSparse(indices, values))
+	// calculate squared distance of the documents from input vector
+	exact := func(e entry) float64 {
+		if doc, ok := e.doc.(sparseDoc); ok {
+			docIndices, docValues := doc.GetSparseVector()
+			return calcSparseSquaredDistance(docIndices, docValues, indices, values)
+		}
+		return calcSparseDenseSquaredDistance(indices, values, e.doc.(Document).GetVector())
+	}
+	return n.rankCandidates(state, handles, k, exact, nil), nil
+}
+
+// GetSparse is used to get single sparse document from index. If
+// document not found or it is a dense document returns ErrNotFound.
+func (n *KNN) GetSparse(docID string) (SparseDocument, error) {
+	// check input validity
+	if len(docID) == 0 {
+		return nil, fmt.Errorf("%w: document id must not empty", ErrInvalidDocument)
+	}
+	// acquire state for reading
+	state, release := n.readState()
+	// defer release
+	defer release()
+
+	// load document from index
+	e, ok := state.load(docID)
+	if !ok || e.expired(time.Now().UnixNano()) {
+		return nil, ErrNotFound
+	}
+	doc, ok := e.doc.(sparseDoc)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	state.evictor.touch(state.handles[docID], docID)
+	return doc.SparseDocument, nil
+}
+
+// validateSparseVector returns error when the sparse vector is
+// empty, or its indices are not ascending or out of range.
+func (n *KNN) validateSparseVector(indices []int, values []float64) error {
+	if len(indices) == 0 {
+		return ErrEmptyVector
+	}
+	if len(indices) != len(values) {
+		return fmt.Errorf("%w: mismatch number of indices & values, indices: %v, values: %v", ErrInvalidVector, len(indices), len(values))
+	}
+	for i, index := range indices {
+		if index < 0 || index >= n.vectorDimension {
+			return fmt.Errorf("%w: index out of range, got: %v, dimension: %v", ErrDimensionMismatch, index, n.vectorDimension)
+		}
+		if i > 0 && index <= indices[i-1] {
+			return fmt.Errorf("%w: indices must be in ascending order", ErrInvalidVector)
+		}
+	}
+	return nil
+}
+
+// calcSparseSquaredDistance is used for calculating squared
+// euclidean distance between two sparse vectors. The indices
+// assummed in ascending order.
+func calcSparseSquaredDistance(indices1 []int, values1 []float64, indices2 []int, values2 []float64) float64 {
+	sum := 0.0
+	i, j := 0, 0
+	for i < len(indices1) || j < len(indices2) {
+		var d float64
+		switch {
+		case j == len(indices2) || (i < len(indices1) && indices1[i] < indices2[j]):
+			d = values1[i]
+			i++
+		case i == len(indices1) || indices2[j] < indices1[i]:
+			d = values2[j]
+			j++
+		default:
+			d = values1[i] - values2[j]
+			i++
+			j++
+		}
+		sum += d * d
+	}
+	return sum
+}
+
+// calcSparseDenseSquaredDistance is used for calculating squared
+// euclidean distance between sparse vector & dense vector. Input
+// `indices` assummed within the dimension of `dense`.
+func calcSparseDenseSquaredDistance(indices []int, values []float64, dense []float64) float64 {
+	sum := 0.0
+	for _, v := range dense {
+		sum += v * v
+	}
+	// replace the contribution of non-zero dimensions
+	for i, index := range indices {
+		d := values[i] - dense[index]
+		sum += d*d - dense[index]*dense[index]
+	}
+	return math.Max(sum, 0)
+}
@@ -0,0 +1,251 @@
+package knn
+
+import "time"
+
+// indexState holds the data of KNN index. It is not safe for
+// concurrent use, KNN guards it with its mutex.
+type indexState struct {
+	// LSH index which will be used for indexing documents
+	lsh *basicLsh
+
+	// We use another storage because LSH index only stores document
+	// handle, so to get full information of document we need to
+	// store it somewhere else.
+	//
+	// The behavior of LSH index is pretty reasonable, because in
+	// the index, the document would be stored in all hash tables
+	// (duplicated). So it's best to just store the necessary info
+	// on the LSH index to minimize memory usage, which in this
+	// case is document handle. The handle is dense uint32 assigned
+	// by the index, it is used as position of document in entries.
+	handles map[string]uint32
+	entries []entry
+
+	// freeHandles holds handles of deleted documents, they
+	// are reused for the next added documents.
+	freeHandles []uint32
+
+	// docBytes holds estimated memory used by the documents,
+	// it is used for enforcing memory limit.
+	docBytes int64
+
+	// nextExpiry holds the lower bound of the earliest expiry time of
+	// the documents, the value of 0 means none of them expires. It is
+	// lowered on insert and only recomputed on sweeping, so the index
+	// knows whether sweeping would find anything without scanning.
+	nextExpiry int64
+
+	// rebuild is not nil when the LSH index is being rebuilt
+	// by Reconfigure, the writes are applied to both indexes.
+	rebuild *rebuild
+
+	// evictor tracks usage of the documents when the index has
+	// capacity, it is shared with the clones since the reads on
+	// the snapshots also count as usage.
+	evictor *evictor
+}
+
+// entry holds single document in the index
+type entry struct {
+	// doc is either Document or sparseDoc, it is nil when
+	// the handle is free
+	doc interface{}
+
+	// codes holds quantized vector of dense document
+	codes []int8
+
+	// keys holds the keys of the document buckets in the top level
+	// hash tables, they are used for removing the document from the
+	// buckets without re-hashing its vector which might have changed
+	keys []uint64
+
+	// expiresAt holds the expiry time of the document in unix
+	// nanoseconds, the value of 0 means it never expires
+	expiresAt int64
+}
+
+// expired returns true when the document in the entry
+// is already expired at `now` (in unix nanoseconds)
+func (e entry) expired(now int64) bool {
+	return e.expiresAt > 0 && e.expiresAt <= now
+}
+
+// expiryOf returns expiry time of `doc` in unix nanoseconds when
+// it implements Expirer, otherwise returns 0
+func expiryOf(doc interface{}) int64 {
+	expirer, ok := doc.(Expirer)
+	if !ok || expirer.GetExpiry().IsZero() {
+		return 0
+	}
+	return expirer.GetExpiry().UnixNano()
+}
+
+func newIndexState(lsh *basicLsh) *indexState {
+	return &indexState{
+		lsh:     lsh,
+		handles: map[string]uint32{},
+	}
+}
+
+// clone returns deep copy of the state
+func (s *indexState) clone() *indexState {
+	c := &indexState{
+		lsh:         s.lsh.clone(),
+		handles:     make(map[string]uint32, len(s.handles)),
+		entries:     append([]entry(nil), s.entries...),
+		freeHandles: append([]uint32(nil), s.freeHandles...),
+		docBytes:    s.docBytes,
+		nextExpiry:  s.nextExpiry,
+		evictor:     s.evictor,
+	}
+	for id, h := range s.handles {
+		c.handles[id] = h
+	}
+	// the LSH of the clone must read points from its own entries
+	if c.lsh.pointOf != nil {
+		c.lsh.pointOf = c.pointOf
+	}
+	return c
+}
+
+// pointOf returns point of document with handle `h`, it is used
+// by the LSH for splitting buckets.
+func (s *indexState) pointOf(h uint32) point {
+	if doc, ok := s.entries[h].doc.(sparseDoc); ok {
+		indices, values := doc.GetSparseVector()
+		return point{indices: indices, values: values}
+	}
+	return point{dense: s.entries[h].doc.(Document).GetVector()}
+}
+
+// hashOf returns hash values of document in entry `e` for `lsh`
+func hashOf(lsh *basicLsh, e entry) hashValues {
+	if doc, ok := e.doc.(sparseDoc); ok {
+		return lsh.hashSparse(doc.GetSparseVector())
+	}
+	return lsh.hash(e.doc.(Document).GetVector())
+}
+
+// insert adds document in entry `e` which hash values is `hvs` to
+// the state. Existing document with the same id will be replaced.
+func (s *indexState) insert(id string, e entry, hvs hashValues) {
+	e.keys = hvs.keys
+	h, ok := s.handles[id]
+	if ok {
+		// remove old document from LSH index
+		s.lsh.delete(s.entries[h].keys, h)
+		s.rebuild.delete(h)
+		s.docBytes -= docBytes(id, s.entries[h])
+		s.evictor.touch(h, id)
+	} else if len(s.freeHandles) > 0 {
+		// reuse handle of deleted document
+		h = s.freeHandles[len(s.freeHandles)-1]
+		s.freeHandles = s.freeHandles[:len(s.freeHandles)-1]
+	} else {
+		h = uint32(len(s.entries))
+		s.entries = append(s.entries, entry{})
+	}
+	if !ok {
+		s.evictor.add(h, id)
+	}
+	s.handles[id] = h
+	s.entries[h] = e
+	s.lsh.insert(hvs, h)
+	s.rebuild.insert(e, h)
+	s.docBytes += docBytes(id, e)
+	if e.expiresAt > 0 && (s.nextExpiry == 0 || e.expiresAt < s.nextExpiry) {
+		s.nextExpiry = e.expiresAt
+	}
+}
+
+// delete removes document with `id` from the state, it returns
+// false when the document is not found.
+func (s *indexState) delete(id string) bool {
+	h, ok := s.handles[id]
+	if !ok {
+		return false
+	}
+	s.lsh.delete(s.entries[h].keys, h)
+	s.rebuild.delete(h)
+	s.evictor.remove(h)
+	s.docBytes -= docBytes(id, s.entries[h])
+	delete(s.handles, id)
+	s.entries[h] = entry{}
+	s.freeHandles = append(s.freeHandles, h)
+	return true
+}
+
+// deleteWhere removes all documents which entries match `match`
+// from the state, it returns the number of removed documents. The
+// hash tables are scanned only once regardless the number of the
+// removed documents.
+func (s *indexState) deleteWhere(match func(id string, e entry) bool) int {
+	removed := newBitset(len(s.entries))
+	count := 0
+	for id, h := range s.handles {
+		if !match(id, s.entries[h]) {
+			continue
+		}
+		removed.set(h)
+		s.evictor.remove(h)
+		count++
+		s.docBytes -= docBytes(id, s.entries[h])
+		delete(s.handles, id)
+		s.entries[h] = entry{}
+		s.freeHandles = append(s.freeHandles, h)
+	}
+	if count == 0 {
+		return 0
+	}
+	s.lsh.deleteAll(removed)
+	if s.rebuild != nil {
+		s.rebuild.lsh.deleteAll(removed)
+		for h := range s.rebuild.keys {
+			if removed.has(uint32(h)) {
+				s.rebuild.keys[h] = nil
+			}
+		}
+	}
+	return count
+}
+
+// reset removes all documents from the state
+func (s *indexState) reset() {
+	s.lsh.reset()
+	if s.rebuild != nil {
+		s.rebuild.lsh.reset()
+		s.rebuild.keys = nil
+	}
+	s.evictor.reset()
+	s.handles = map[string]uint32{}
+	s.entries = nil
+	s.freeHandles = nil
+	s.docBytes = 0
+	s.nextExpiry = 0
+}
+
+// load returns entry of document with `id`
+func (s *indexState) load(id string) (entry, bool) {
+	h, ok := s.handles[id]
+	if !ok {
+		return entry{}, false
+	}
+	return s.entries[h], true
+}
+
+// query returns handles of candidates for query point which hash
+// values is `hvs`. The expired documents are excluded.
+func (s *indexState) query(hvs hashValues) []uint32 {
+	seen := acquireBitset(len(s.entries))
+	handles := s.lsh.query(hvs, *seen)
+	releaseBitset(seen, handles)
+	// remove expired documents
+	now := time.Now().UnixNano()
+	live := handles[:0]
+	for _, h := range handles {
+		if !s.entries[h].expired(now) {
+			live = append(live, h)
+		}
+	}
+	return live
+}
@@ -0,0 +1,205 @@
+package knn
+
+import (
+	"sort"
+	"sync/atomic"
+)
+
+// The memory usage is estimated from the size of the data structures
+// used by the index, the constants below are approximation of Go
+// runtime overhead on 64-bit platforms.
+const (
+	// map entry of hash table, chain slice & bucket struct
+	bucketOverheadBytes = 112
+	// single handle in a bucket
+	handleBytes = 4
+	// map entry of id to handle & element of entries
+	entryOverheadBytes = 80
+	// slice header of vector
+	sliceHeaderBytes = 24
+)
+
+// Stats holds statistics of KNN index. All sizes are in bytes &
+// they are estimation, the actual memory usage might be different
+// depending on the runtime.
+type Stats struct {
+	NumDocuments int
+
+	// HashTableBytes is the size of hash tables & their buckets
+	// excluding the document handles held by the buckets
+	HashTableBytes int64
+
+	// BucketBytes is the size of document handles in the buckets
+	BucketBytes int64
+
+	// DocumentBytes is the size of document ids & their entries
+	DocumentBytes int64
+
+	// VectorBytes is the size of document vectors, including the
+	// quantized vectors
+	VectorBytes int64
+
+	// SnapshotBytes is the size of the published snapshot in snapshot
+	// isolation mode, it only holds copy of the hash tables & the
+	// document handles since the documents are shared with the index
+	SnapshotBytes int64
+
+	// TotalBytes is the sum of all sizes above
+	TotalBytes int64
+
+	// NumEvictions is the number of documents evicted since the
+	// index is created, check Configs.Capacity
+	NumEvictions uint64
+
+	// Tables holds statistics of each hash table
+	Tables []TableStats
+}
+
+// TableStats holds statistics of single hash table
+type TableStats struct {
+	// NumBuckets includes the buckets in sub-tables of split buckets
+	NumBuckets        int
+	LargestBucketSize int
+
+	// NumSplitBuckets is the number of buckets which documents
+	// have been moved into sub-table, check Configs.MaxBucketSize
+	NumSplitBuckets int
+}
+
+// BucketStats holds statistics of single bucket
+type BucketStats struct {
+	// Table is the index of hash table of the bucket
+	Table int
+	// Size is the number of documents in the bucket
+	Size int
+	// Depth is the number of times the bucket has been split,
+	// bucket in the top level hash table has depth 0
+	Depth int
+}
+
+// Stats returns statistics of the index including its
+// estimated memory usage
+func (n *KNN) Stats() Stats {
+	// acquire read lock
+	n.mux.RLock()
+	// defer read unlock
+	defer n.mux.RUnlock()
+
+	state := n.state
+	stats := Stats{
+		NumDocuments: len(state.handles),
+		NumEvictions: atomic.LoadUint64(&n.numEvictions),
+		Tables:       make([]TableStats, len(state.lsh.tables)),
+	}
+	for i, table := range state.lsh.tables {
+		walkBuckets(table, 0, func(b *bucket, depth int) {
+			stats.Tables[i].NumBuckets++
+			if b.sub != nil {
+				stats.Tables[i].NumSplitBuckets++
+			}
+			if len(b.handles) > stats.Tables[i].LargestBucketSize {
+				stats.Tables[i].LargestBucketSize = len(b.handles)
+			}
+			stats.HashTableBytes += bucketOverheadBytes + int64(8*len(b.values))
+			stats.BucketBytes += int64(handleBytes * len(b.handles))
+		})
+	}
+	for id, h := range state.handles {
+		stats.DocumentBytes += entryOverheadBytes + int64(len(id)) + keysBytes(state.entries[h])
+		stats.VectorBytes += vectorBytes(state.entries[h])
+	}
+	stats.SnapshotBytes = n.snapshotBytes
+	stats.TotalBytes = stats.HashTableBytes + stats.BucketBytes + stats.DocumentBytes + stats.VectorBytes + stats.SnapshotBytes
+	return stats
+}
+
+// OversizedBuckets returns buckets which hold at least `minSize`
+// documents, sorted from the largest. It is useful for finding
+// skew in the data distribution, since queries landing on large
+// bucket need to compare many candidates.
+func (n *KNN) OversizedBuckets(minSize int) []BucketStats {
+	// acquire read lock
+	n.mux.RLock()
+	// defer read unlock
+	defer n.mux.RUnlock()
+
+	buckets := make([]BucketStats, 0)
+	for i, table := range n.state.lsh.tables {
+		walkBuckets(table, 0, func(b *bucket, depth int) {
+			if b.sub == nil && len(b.handles) >= minSize {
+				buckets = append(buckets, BucketStats{Table: i, Size: len(b.handles), Depth: depth})
+			}
+		})
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].Size > buckets[j].Size
+	})
+	return buckets
+}
+
+// memoryUsage returns estimated memory usage of the state, it is
+// cheaper than Stats since it only uses the counters.
+func (s *indexState) memoryUsage() int64 {
+	numBuckets := s.lsh.numBuckets
+	return numBuckets*int64(bucketOverheadBytes+8*s.lsh.m) +
+		int64(len(s.handles)*s.lsh.l*handleBytes) +
+		s.docBytes
+}
+
+// cloneBytes returns estimated memory used by the state when it is
+// a clone, the clone has its own hash tables & document handles but
+// the bucket values, the document ids & the documents are shared.
+func (s *indexState) cloneBytes() int64 {
+	return s.lsh.numBuckets*bucketOverheadBytes +
+		int64(len(s.handles)*s.lsh.l*handleBytes) +
+		int64(len(s.handles)*entryOverheadBytes)
+}
+
+// docBytes returns estimated memory used by document in entry
+// `e` excluding the hash tables.
+func docBytes(id string, e entry) int64 {
+	return entryOverheadBytes + int64(len(id)) + keysBytes(e) + vectorBytes(e)
+}
+
+// keysBytes returns estimated memory used by the bucket keys
+// held by entry `e`
+func keysBytes(e entry) int64 {
+	return sliceHeaderBytes + int64(8*len(e.keys))
+}
+
+// vectorBytes returns estimated memory used by vectors of
+// document in entry `e`.
+func vectorBytes(e entry) int64 {
+	size := int64(0)
+	switch doc := e.doc.(type) {
+	case sparseDoc:
+		indices, values := doc.GetSparseVector()
+		size += 2*sliceHeaderBytes + int64(8*len(indices)+8*len(values))
+	case *quantizedDoc:
+		// the vector is only held as the codes below
+	case Document:
+		size += sliceHeaderBytes + int64(8*len(doc.GetVector()))
+	}
+	if e.codes != nil {
+		size += sliceHeaderBytes + int64(len(e.codes))
+	}
+	return size
+}
+
+// checkMemoryLimit returns ErrMemoryLimit when inserting document
+// in entry `e` would exceed the memory limit.
+func (n *KNN) checkMemoryLimit(id string, e entry) error {
+	if n.memoryLimit <= 0 {
+		return nil
+	}
+	// estimate the worst case where the document
+	// creates new bucket in every table
+	delta := docBytes(id, e) + int64(n.state.lsh.l*(bucketOverheadBytes+8*n.state.lsh.m+handleBytes))
+	if old, ok := n.state.load(id); ok {
+		delta -= docBytes(id, old)
+	}
+	if n.state.memoryUsage()+n.snapshotBytes+delta > n.memoryLimit {
+		return ErrMemoryLimit
+	}
+	return nil
+}
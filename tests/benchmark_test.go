@@ -0,0 +1,111 @@
+package test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/riandyrn/go-knn"
+)
+
+func BenchmarkAdd(b *testing.B) {
+	dim := 100
+	documents := getMockDocuments(1000, dim)
+	for _, numHashTable := range []int{1, 4, 16, 64} {
+		for _, numWorkers := range []int{0, 4, 16} {
+			b.Run(fmt.Sprintf("NumHashTable=%v/NumWorkers=%v", numHashTable, numWorkers), func(b *testing.B) {
+				index := newIndex(b, knn.Configs{
+					VectorDimension: dim,
+					NumHashTable:    numHashTable,
+					NumHyperplane:   10,
+					SlotSize:        20,
+					NumWorkers:      numWorkers,
+				})
+				defer index.Close()
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					index.Add(documents[i%len(documents)])
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkDelete(b *testing.B) {
+	dim := 100
+	documents := getMockDocuments(1000, dim)
+	for _, numHashTable := range []int{1, 4, 16, 64} {
+		for _, numWorkers := range []int{0, 4, 16} {
+			b.Run(fmt.Sprintf("NumHashTable=%v/NumWorkers=%v", numHashTable, numWorkers), func(b *testing.B) {
+				index := newIndex(b, knn.Configs{
+					VectorDimension: dim,
+					NumHashTable:    numHashTable,
+					NumHyperplane:   10,
+					SlotSize:        20,
+					NumWorkers:      numWorkers,
+				})
+				defer index.Close()
+				for _, document := range documents {
+					index.Add(document)
+				}
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					index.Delete(documents[i%len(documents)].GetID())
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkAddEvict(b *testing.B) {
+	dim := 10
+	documents := getMockDocuments(20000, dim)
+	index := newIndex(b, knn.Configs{
+		VectorDimension: dim,
+		NumHashTable:    1,
+		NumHyperplane:   10,
+		SlotSize:        20,
+		Capacity:        len(documents) / 2,
+	})
+	defer index.Close()
+	// the document which expires later must not make
+	// the eviction scan the index
+	index.AddWithTTL(documents[0], time.Hour)
+	for _, document := range documents[1 : len(documents)/2] {
+		index.Add(document)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		index.Add(documents[(len(documents)/2+i)%len(documents)])
+	}
+}
+
+func BenchmarkQuery(b *testing.B) {
+	dim := 100
+	documents := getMockDocuments(10000, dim)
+	queryVectors := make([][]float64, 0, 100)
+	for i := 0; i < cap(queryVectors); i++ {
+		queryVectors = append(queryVectors, getRandomVector(dim))
+	}
+	for _, numHashTable := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("NumHashTable=%v", numHashTable), func(b *testing.B) {
+			index := newIndex(b, knn.Configs{
+				VectorDimension: dim,
+				NumHashTable:    numHashTable,
+				NumHyperplane:   10,
+				SlotSize:        20,
+			})
+			for _, document := range documents {
+				index.Add(document)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				index.Query(queryVectors[i%len(queryVectors)], 10)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/riandyrn/go-knn"
+)
+
+// newBinaryIndex returns BinaryKNN index with `configs`, it
+// fails the test when the configs are invalid
+func newBinaryIndex(t *testing.T, configs knn.BinaryConfigs) *knn.BinaryKNN {
+	index, err := knn.NewBinaryKNN(configs)
+	if err != nil {
+		t.Fatalf("unable to create index due: %v", err)
+	}
+	return index
+}
+
+func TestNewBinaryKNN(t *testing.T) {
+	testCases := []struct {
+		Name      string
+		Configs   knn.BinaryConfigs
+		ExpErrNil bool
+	}{
+		{
+			Name:      "Test Zero Bits",
+			Configs:   knn.BinaryConfigs{NumBits: 0, NumHashTable: 2, NumSampledBits: 8},
+			ExpErrNil: false,
+		},
+		{
+			Name:      "Test Zero Tables",
+			Configs:   knn.BinaryConfigs{NumBits: 64, NumHashTable: 0, NumSampledBits: 8},
+			ExpErrNil: false,
+		},
+		{
+			Name:      "Test Zero Sampled Bits",
+			Configs:   knn.BinaryConfigs{NumBits: 64, NumHashTable: 2, NumSampledBits: 0},
+			ExpErrNil: false,
+		},
+		{
+			Name:      "Test Too Many Sampled Bits",
+			Configs:   knn.BinaryConfigs{NumBits: 128, NumHashTable: 2, NumSampledBits: 65},
+			ExpErrNil: false,
+		},
+		{
+			Name:      "Test Normal Configs",
+			Configs:   knn.BinaryConfigs{NumBits: 64, NumHashTable: 2, NumSampledBits: 8},
+			ExpErrNil: true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			_, err := knn.NewBinaryKNN(testCase.Configs)
+			if (err == nil) != testCase.ExpErrNil {
+				t.Fatalf("unexpected error for case: %+v, err: %v", testCase, err)
+			}
+		})
+	}
+}
+
+func TestBinaryAdd(t *testing.T) {
+	testCases := []struct {
+		Name      string
+		InputDoc  knn.BinaryDocument
+		ExpErrNil bool
+	}{
+		{
+			Name:      "Test Nil Document",
+			InputDoc:  nil,
+			ExpErrNil: false,
+		},
+		{
+			Name:      "Test Empty ID",
+			InputDoc:  newMockBinaryDoc("", []uint64{1, 2}),
+			ExpErrNil: false,
+		},
+		{
+			Name:      "Test Mismatch Number of Words",
+			InputDoc:  newMockBinaryDoc("image_1.jpeg", []uint64{1}),
+			ExpErrNil: false,
+		},
+		{
+			Name:      "Test Normal Document",
+			InputDoc:  newMockBinaryDoc("image_1.jpeg", []uint64{1, 2}),
+			ExpErrNil: true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			index := newBinaryIndex(t, knn.BinaryConfigs{
+				NumBits:        128,
+				NumHashTable:   2,
+				NumSampledBits: 8,
+			})
+			err := index.Add(testCase.InputDoc)
+			if (err == nil) != testCase.ExpErrNil {
+				t.Fatalf("unexpected error for case: %+v, err: %v", testCase, err)
+			}
+		})
+	}
+}
+
+func TestBinaryQuery(t *testing.T) {
+	// prepare documents
+	docs := []*mockBinaryDoc{
+		newMockBinaryDoc("image_1", []uint64{0xf0f0f0f0f0f0f0f0}),
+		newMockBinaryDoc("image_2", []uint64{0xf0f0f0f0f0f0f0f1}),
+		newMockBinaryDoc("image_3", []uint64{0xf0f0f0f0f0f0f0f3}),
+		newMockBinaryDoc("image_4", []uint64{0x0f0f0f0f0f0f0f0f}),
+	}
+	// initialize knn index
+	index := newBinaryIndex(t, knn.BinaryConfigs{
+		NumBits:        64,
+		NumHashTable:   10,
+		NumSampledBits: 4,
+	})
+	for _, doc := range docs {
+		index.Add(doc)
+	}
+	// execute query
+	resultDocs, err := index.Query(docs[0].GetBits(), 3)
+	if err != nil {
+		t.Fatalf("unexpected error, err: %v", err)
+	}
+	// examine result
+	expIDs := []string{"image_1", "image_2", "image_3"}
+	expDistances := []int{0, 1, 2}
+	if len(resultDocs) != len(expIDs) {
+		t.Fatalf("unexpected number of result, expected: %v, got: %v", len(expIDs), len(resultDocs))
+	}
+	for i := range expIDs {
+		if resultDocs[i].Document.GetID() != expIDs[i] || resultDocs[i].Distance != expDistances[i] {
+			t.Fatalf("unexpected result at %v, expected: %v (%v), got: %v (%v)", i, expIDs[i], expDistances[i], resultDocs[i].Document.GetID(), resultDocs[i].Distance)
+		}
+	}
+	// delete document & make sure it is no longer returned
+	if err := index.Delete("image_1"); err != nil {
+		t.Fatalf("unexpected error, err: %v", err)
+	}
+	resultDocs, err = index.Query(docs[0].GetBits(), 3)
+	if err != nil {
+		t.Fatalf("unexpected error, err: %v", err)
+	}
+	for _, resultDoc := range resultDocs {
+		if resultDoc.Document.GetID() == "image_1" {
+			t.Fatalf("deleted document still found on result")
+		}
+	}
+}
+
+func TestBinaryQueryIgnoresTailBits(t *testing.T) {
+	// only the lowest 4 bits are used, the other bits of the
+	// word must not affect the distance
+	index := newBinaryIndex(t, knn.BinaryConfigs{
+		NumBits:        4,
+		NumHashTable:   1,
+		NumSampledBits: 1,
+	})
+	index.Add(newMockBinaryDoc("image_1", []uint64{0xff00000000000001}))
+	resultDocs, err := index.Query([]uint64{0x1}, 1)
+	if err != nil {
+		t.Fatalf("unexpected error, err: %v", err)
+	}
+	if len(resultDocs) != 1 || resultDocs[0].Distance != 0 {
+		t.Fatalf("unexpected result, expected distance 0, got: %+v", resultDocs)
+	}
+}
+
+func TestBinaryDeleteMutatedBits(t *testing.T) {
+	index := newBinaryIndex(t, knn.BinaryConfigs{
+		NumBits:        64,
+		NumHashTable:   4,
+		NumSampledBits: 8,
+	})
+	doc := newMockBinaryDoc("image_1", []uint64{0})
+	index.Add(doc)
+	// mutate the bits after the document is added, the document
+	// must still be removed from its original buckets
+	doc.bits = []uint64{0xffffffffffffffff}
+	if err := index.Delete("image_1"); err != nil {
+		t.Fatalf("unexpected error, err: %v", err)
+	}
+	index.Add(newMockBinaryDoc("image_1", []uint64{0xffffffffffffffff}))
+	resultDocs, err := index.Query([]uint64{0}, 1)
+	if err != nil {
+		t.Fatalf("unexpected error, err: %v", err)
+	}
+	if len(resultDocs) != 0 {
+		t.Fatalf("unexpected result from stale bucket: %+v", resultDocs)
+	}
+}
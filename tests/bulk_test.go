@@ -0,0 +1,91 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/riandyrn/go-knn"
+)
+
+func TestDeleteWhere(t *testing.T) {
+	// prepare index, use small bucket size so
+	// deletion from split buckets is covered
+	n := 2000
+	dim := 10
+	index := newIndex(t, knn.Configs{
+		VectorDimension: dim,
+		NumHashTable:    4,
+		NumHyperplane:   2,
+		SlotSize:        20,
+		MaxBucketSize:   50,
+	})
+	documents := getMockDocuments(n, dim)
+	for _, document := range documents {
+		index.Add(document)
+	}
+	// delete documents with id ends with 0
+	match := func(doc knn.Document) bool {
+		return strings.HasSuffix(doc.GetID(), "0")
+	}
+	count, err := index.DeleteWhere(match)
+	if err != nil {
+		t.Fatalf("unable to delete documents due: %v", err)
+	}
+	if count != n/10 {
+		t.Fatalf("unexpected number of deleted documents, expected: %v, got: %v", n/10, count)
+	}
+	if index.Len() != n-n/10 {
+		t.Fatalf("unexpected length, expected: %v, got: %v", n-n/10, index.Len())
+	}
+	for _, document := range documents {
+		resultDocs, _ := index.Query(document.GetVector(), 1)
+		found := len(resultDocs) == 1 && resultDocs[0].Document.GetID() == document.GetID()
+		if found == match(document) {
+			t.Fatalf("unexpected query result of document %v, found: %v", document.GetID(), found)
+		}
+	}
+	// delete the rest
+	count, _ = index.DeleteWhere(func(doc knn.Document) bool { return true })
+	if count != n-n/10 {
+		t.Fatalf("unexpected number of deleted documents, expected: %v, got: %v", n-n/10, count)
+	}
+	stats := index.Stats()
+	if stats.NumDocuments != 0 || stats.TotalBytes != 0 {
+		t.Fatalf("unexpected stats after deleting all documents: %+v", stats)
+	}
+	if _, err := index.DeleteWhere(nil); err == nil {
+		t.Fatalf("expecting error on nil match function")
+	}
+}
+
+func TestReset(t *testing.T) {
+	// prepare index
+	n := 1000
+	dim := 10
+	index := newIndex(t, knn.Configs{
+		VectorDimension: dim,
+		NumHashTable:    4,
+		NumHyperplane:   4,
+		SlotSize:        5,
+	})
+	documents := getMockDocuments(n, dim)
+	for _, document := range documents {
+		index.Add(document)
+	}
+	index.Reset()
+	stats := index.Stats()
+	if stats.NumDocuments != 0 || stats.TotalBytes != 0 {
+		t.Fatalf("unexpected stats after reset: %+v", stats)
+	}
+	resultDocs, _ := index.Query(documents[0].GetVector(), 1)
+	if len(resultDocs) != 0 {
+		t.Fatalf("unexpected query result after reset: %v", resultDocs)
+	}
+	// check index is still usable
+	for _, document := range documents {
+		index.Add(document)
+	}
+	if index.Len() != n {
+		t.Fatalf("unexpected length, expected: %v, got: %v", n, index.Len())
+	}
+}
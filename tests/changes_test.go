@@ -0,0 +1,181 @@
+package test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/riandyrn/go-knn"
+)
+
+type mockHook struct {
+	mux    sync.Mutex
+	events []knn.ChangeEvent
+}
+
+func (h *mockHook) OnAdd(event knn.ChangeEvent) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	h.events = append(h.events, event)
+}
+
+func (h *mockHook) OnDelete(event knn.ChangeEvent) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	h.events = append(h.events, event)
+}
+
+func TestHook(t *testing.T) {
+	// prepare index which could only hold 2 documents
+	dim := 10
+	index := newIndex(t, knn.Configs{
+		VectorDimension: dim,
+		NumHashTable:    2,
+		NumHyperplane:   2,
+		SlotSize:        5,
+		Capacity:        2,
+	})
+	hook := &mockHook{}
+	index.RegisterHook(hook)
+	// apply mutations
+	index.Add(newMockDoc("doc_1", getRandomVector(dim)))
+	index.Add(newMockDoc("doc_2", getRandomVector(dim)))
+	index.Add(newMockDoc("doc_1", getRandomVector(dim)))
+	index.Add(newMockDoc("doc_3", getRandomVector(dim)))
+	index.Delete("doc_1")
+	index.Delete("doc_4")
+	index.Reset()
+	// check events
+	expEvents := []struct {
+		Op knn.ChangeOp
+		ID string
+	}{
+		{Op: knn.OpAdd, ID: "doc_1"},
+		{Op: knn.OpAdd, ID: "doc_2"},
+		{Op: knn.OpUpsert, ID: "doc_1"},
+		{Op: knn.OpEvict, ID: "doc_2"},
+		{Op: knn.OpAdd, ID: "doc_3"},
+		{Op: knn.OpDelete, ID: "doc_1"},
+		{Op: knn.OpReset, ID: ""},
+	}
+	if len(hook.events) != len(expEvents) {
+		t.Fatalf("unexpected number of events, expected: %v, got: %v", len(expEvents), len(hook.events))
+	}
+	for i, event := range hook.events {
+		if event.Op != expEvents[i].Op || event.ID != expEvents[i].ID || event.Seq != uint64(i+1) {
+			t.Fatalf("unexpected event at %v, expected: %+v, got: %v %v %v", i, expEvents[i], event.Seq, event.Op, event.ID)
+		}
+		if event.Op != knn.OpReset && event.Document.GetID() != event.ID {
+			t.Fatalf("unexpected document of event at %v, got: %v", i, event.Document.GetID())
+		}
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	// prepare index
+	n := 1000
+	dim := 10
+	index := newIndex(t, knn.Configs{
+		VectorDimension: dim,
+		NumHashTable:    2,
+		NumHyperplane:   2,
+		SlotSize:        5,
+	})
+	blocking := index.Subscribe(10, knn.BackpressureBlock)
+	dropping := index.Subscribe(10, knn.BackpressureDrop)
+	// consume blocking subscription concurrently
+	var wg sync.WaitGroup
+	wg.Add(1)
+	received := 0
+	go func() {
+		defer wg.Done()
+		lastSeq := uint64(0)
+		for event := range blocking.C {
+			if event.Seq != lastSeq+1 {
+				t.Errorf("unexpected sequence number, expected: %v, got: %v", lastSeq+1, event.Seq)
+			}
+			lastSeq = event.Seq
+			received++
+		}
+	}()
+	// write concurrently
+	var writers sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		writers.Add(1)
+		go func(i int) {
+			defer writers.Done()
+			for j := 0; j < n/4; j++ {
+				index.Add(newMockDoc(fmt.Sprintf("doc_%v_%v", i, j), getRandomVector(dim)))
+			}
+		}(i)
+	}
+	writers.Wait()
+	// wait until all events are received
+	deadline := time.Now().Add(time.Second)
+	for len(blocking.C) > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	blocking.Close()
+	wg.Wait()
+	if received != n {
+		t.Fatalf("unexpected number of received events, expected: %v, got: %v", n, received)
+	}
+	// the dropping subscription is never consumed
+	if len(dropping.C) != 10 || dropping.Dropped() != uint64(n-10) {
+		t.Fatalf("unexpected dropping subscription, buffered: %v, dropped: %v", len(dropping.C), dropping.Dropped())
+	}
+	dropping.Close()
+	// closed subscription must not block the writes
+	index.Add(newMockDoc("doc_last", getRandomVector(dim)))
+}
+
+// reentrantHook closes subscription, subscribes & registers other
+// hook on the first event it receives
+type reentrantHook struct {
+	index *knn.KNN
+	sub   *knn.Subscription
+	hook  *mockHook
+	once  sync.Once
+}
+
+func (h *reentrantHook) OnAdd(event knn.ChangeEvent) {
+	h.once.Do(func() {
+		h.sub.Close()
+		h.index.Subscribe(10, knn.BackpressureDrop).Close()
+		h.index.RegisterHook(h.hook)
+	})
+}
+
+func (h *reentrantHook) OnDelete(event knn.ChangeEvent) {}
+
+func TestHookReentrant(t *testing.T) {
+	dim := 10
+	index := newIndex(t, knn.Configs{
+		VectorDimension: dim,
+		NumHashTable:    2,
+		NumHyperplane:   2,
+		SlotSize:        5,
+	})
+	hook := &reentrantHook{
+		index: index,
+		sub:   index.Subscribe(0, knn.BackpressureBlock),
+		hook:  &mockHook{},
+	}
+	index.RegisterHook(hook)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		index.Add(newMockDoc("doc_1", getRandomVector(dim)))
+		index.Add(newMockDoc("doc_2", getRandomVector(dim)))
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("hook is deadlocked")
+	}
+	// the hook registered by the hook receives the next events
+	if len(hook.hook.events) != 1 || hook.hook.events[0].ID != "doc_2" {
+		t.Fatalf("unexpected events of registered hook: %+v", hook.hook.events)
+	}
+}
@@ -0,0 +1,96 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/riandyrn/go-knn"
+)
+
+func TestErrors(t *testing.T) {
+	dim := 10
+	index := newIndex(t, knn.Configs{
+		VectorDimension: dim,
+		NumHashTable:    2,
+		NumHyperplane:   2,
+		SlotSize:        3,
+	})
+	index.Add(newMockDoc("doc_1", getRandomVector(dim)))
+
+	testCases := []struct {
+		Name   string
+		Err    error
+		ExpErr error
+	}{
+		{
+			Name:   "Test Add Nil Document",
+			Err:    index.Add(nil),
+			ExpErr: knn.ErrInvalidDocument,
+		},
+		{
+			Name:   "Test Add Empty ID",
+			Err:    index.Add(newMockDoc("", getRandomVector(dim))),
+			ExpErr: knn.ErrInvalidDocument,
+		},
+		{
+			Name:   "Test Add Mismatch Dimension",
+			Err:    index.Add(newMockDoc("doc_2", getRandomVector(dim+1))),
+			ExpErr: knn.ErrDimensionMismatch,
+		},
+		{
+			Name: "Test Query Empty Vector",
+			Err: func() error {
+				_, err := index.Query(nil, 1)
+				return err
+			}(),
+			ExpErr: knn.ErrEmptyVector,
+		},
+		{
+			Name: "Test Query Mismatch Dimension",
+			Err: func() error {
+				_, err := index.Query(getRandomVector(dim-1), 1)
+				return err
+			}(),
+			ExpErr: knn.ErrDimensionMismatch,
+		},
+		{
+			Name: "Test Query Invalid K",
+			Err: func() error {
+				_, err := index.Query(getRandomVector(dim), 0)
+				return err
+			}(),
+			ExpErr: knn.ErrInvalidK,
+		},
+		{
+			Name: "Test Get Non-Existing Document",
+			Err: func() error {
+				_, err := index.Get("doc_2")
+				return err
+			}(),
+			ExpErr: knn.ErrNotFound,
+		},
+		{
+			Name:   "Test Delete Non-Existing Document",
+			Err:    index.Delete("doc_2"),
+			ExpErr: knn.ErrNotFound,
+		},
+		{
+			Name:   "Test Delete Existing Document",
+			Err:    index.Delete("doc_1"),
+			ExpErr: nil,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			if !errors.Is(testCase.Err, testCase.ExpErr) {
+				t.Fatalf("unexpected error, expected: %v, got: %v", testCase.ExpErr, testCase.Err)
+			}
+		})
+	}
+	// check dimensions could be taken from the error
+	var dimErr *knn.DimensionError
+	err := index.Add(newMockDoc("doc_2", getRandomVector(dim+1)))
+	if !errors.As(err, &dimErr) || dimErr.Expected != dim || dimErr.Got != dim+1 {
+		t.Fatalf("unexpected dimension error: %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/riandyrn/go-knn"
+)
+
+func TestEviction(t *testing.T) {
+	dim := 10
+	vectors := make([][]float64, 4)
+	for i := range vectors {
+		vectors[i] = getRandomVector(dim)
+	}
+	testCases := []struct {
+		Name      string
+		Policy    knn.EvictionPolicy
+		Use       func(index *knn.KNN)
+		ExpEvicts []string
+	}{
+		{
+			Name:      "Test LRU Without Usage",
+			Policy:    knn.EvictLRU,
+			Use:       func(index *knn.KNN) {},
+			ExpEvicts: []string{"doc_0"},
+		},
+		{
+			Name:   "Test LRU Get",
+			Policy: knn.EvictLRU,
+			Use: func(index *knn.KNN) {
+				index.Get("doc_0")
+			},
+			ExpEvicts: []string{"doc_1"},
+		},
+		{
+			Name:   "Test LRU Query",
+			Policy: knn.EvictLRU,
+			Use: func(index *knn.KNN) {
+				index.Query(vectors[0], 1)
+				index.Query(vectors[1], 1)
+			},
+			ExpEvicts: []string{"doc_2"},
+		},
+		{
+			Name:   "Test LFU",
+			Policy: knn.EvictLFU,
+			Use: func(index *knn.KNN) {
+				// doc_1 & doc_2 have the same frequency, doc_1
+				// is used less recently so it must be evicted
+				index.Get("doc_0")
+				index.Get("doc_0")
+				index.Get("doc_1")
+				index.Get("doc_2")
+			},
+			ExpEvicts: []string{"doc_1"},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			// initialize index which could only hold 3 documents,
+			// all of them fall into the same bucket
+			var evicted []string
+			index := newIndex(t, knn.Configs{
+				VectorDimension: dim,
+				NumHashTable:    1,
+				NumHyperplane:   2,
+				SlotSize:        1000,
+				Capacity:        3,
+				EvictionPolicy:  testCase.Policy,
+				OnEvict: func(docID string) {
+					evicted = append(evicted, docID)
+				},
+			})
+			for i := 0; i < 3; i++ {
+				index.Add(newMockDoc(fmt.Sprintf("doc_%v", i), vectors[i]))
+			}
+			testCase.Use(index)
+			// add new document to full index
+			if err := index.Add(newMockDoc("doc_3", vectors[3])); err != nil {
+				t.Fatalf("unable to add document due: %v", err)
+			}
+			if fmt.Sprint(evicted) != fmt.Sprint(testCase.ExpEvicts) {
+				t.Fatalf("unexpected evicted documents, expected: %v, got: %v", testCase.ExpEvicts, evicted)
+			}
+			if index.Len() != 3 {
+				t.Fatalf("unexpected length, expected: %v, got: %v", 3, index.Len())
+			}
+			if _, err := index.Get(testCase.ExpEvicts[0]); !errors.Is(err, knn.ErrNotFound) {
+				t.Fatalf("evicted document still found on index")
+			}
+			if stats := index.Stats(); stats.NumEvictions != 1 {
+				t.Fatalf("unexpected number of evictions, expected: %v, got: %v", 1, stats.NumEvictions)
+			}
+			// replacing document must not evict
+			index.Add(newMockDoc("doc_3", vectors[3]))
+			if len(evicted) != 1 {
+				t.Fatalf("unexpected eviction on replacing document: %v", evicted)
+			}
+		})
+	}
+}
+
+func TestEvictExpiredFirst(t *testing.T) {
+	dim := 10
+	var evicted []string
+	index := newIndex(t, knn.Configs{
+		VectorDimension: dim,
+		NumHashTable:    1,
+		NumHyperplane:   2,
+		SlotSize:        1000,
+		Capacity:        3,
+		OnEvict: func(docID string) {
+			evicted = append(evicted, docID)
+		},
+	})
+	index.Add(newMockDoc("doc_0", getRandomVector(dim)))
+	index.Add(newMockDoc("doc_1", getRandomVector(dim)))
+	index.AddWithTTL(newMockDoc("doc_2", getRandomVector(dim)), 50*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	// the expired document makes a room, so nothing is evicted
+	if err := index.Add(newMockDoc("doc_3", getRandomVector(dim))); err != nil {
+		t.Fatalf("unable to add document due: %v", err)
+	}
+	if len(evicted) > 0 {
+		t.Fatalf("unexpected evicted documents: %v", evicted)
+	}
+	if index.Len() != 3 {
+		t.Fatalf("unexpected length, expected: %v, got: %v", 3, index.Len())
+	}
+}
+
+func TestEvictionStaleSnapshot(t *testing.T) {
+	dim := 10
+	var evicted []string
+	index := newIndex(t, knn.Configs{
+		VectorDimension:   dim,
+		NumHashTable:      1,
+		NumHyperplane:     2,
+		SlotSize:          1000,
+		Capacity:          3,
+		SnapshotIsolation: true,
+		OnEvict: func(docID string) {
+			evicted = append(evicted, docID)
+		},
+	})
+	for i := 0; i < 3; i++ {
+		index.Add(newMockDoc(fmt.Sprintf("doc_%v", i), getRandomVector(dim)))
+	}
+	index.Commit()
+	// doc_x reuses handle of doc_0 which is still in the snapshot
+	index.Delete("doc_0")
+	index.Add(newMockDoc("doc_x", getRandomVector(dim)))
+	index.Get("doc_1")
+	index.Get("doc_2")
+	// reading doc_0 from the snapshot must not touch doc_x
+	if _, err := index.Get("doc_0"); err != nil {
+		t.Fatalf("unable to get document from snapshot due: %v", err)
+	}
+	index.Add(newMockDoc("doc_3", getRandomVector(dim)))
+	if fmt.Sprint(evicted) != "[doc_x]" {
+		t.Fatalf("unexpected evicted documents, expected: %v, got: %v", "[doc_x]", evicted)
+	}
+}
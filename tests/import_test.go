@@ -0,0 +1,239 @@
+package test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/riandyrn/go-knn"
+	knnio "github.com/riandyrn/go-knn/io"
+)
+
+// newImportIndex returns index with dimension 3 for import tests
+func newImportIndex(t *testing.T) *knn.KNN {
+	return newIndex(t, knn.Configs{VectorDimension: 3, NumHashTable: 2, NumHyperplane: 2, SlotSize: 5})
+}
+
+func TestImportJSONL(t *testing.T) {
+	input := strings.Join([]string{
+		`{"id": "doc_1", "vector": [1, 2, 3], "metadata": {"label": "cat"}}`,
+		`{"id": "doc_2", "vector": [1, 2]}`,
+		`not json`,
+		``,
+		`{"vector": [1, 2, 3]}`,
+		`{"id": "doc_3", "vector": [4, 5, 6]}`,
+		`{"id": "doc_4", "vector": ["a", 5, 6]}`,
+		`{"id": "doc_5", "vector": [7, 8, 9]}`,
+	}, "\n")
+	var reported []int
+	index := newImportIndex(t)
+	result, err := knnio.ImportJSONL(index, strings.NewReader(input), knnio.Options{
+		BatchSize: 2,
+		OnError:   func(err *knnio.LineError) { reported = append(reported, err.Line) },
+	})
+	if err != nil {
+		t.Fatalf("unable to import due: %v", err)
+	}
+	if result.NumAdded != 3 || result.NumFailed != 4 || index.Len() != 3 {
+		t.Fatalf("unexpected result, added: %v, failed: %v, len: %v", result.NumAdded, result.NumFailed, index.Len())
+	}
+	expLines := []int{2, 3, 5, 7}
+	lines := []int{}
+	for _, lineErr := range result.Errors {
+		lines = append(lines, lineErr.Line)
+	}
+	if !reflect.DeepEqual(expLines, lines) || !reflect.DeepEqual(expLines, reported) {
+		t.Fatalf("unexpected error lines, expected: %v, got: %v, reported: %v", expLines, lines, reported)
+	}
+	if !errors.Is(result.Errors[0], knn.ErrDimensionMismatch) {
+		t.Fatalf("unexpected error, expected: %v, got: %v", knn.ErrDimensionMismatch, result.Errors[0])
+	}
+	if !errors.Is(result.Errors[1], knn.ErrInvalidDocument) {
+		t.Fatalf("unexpected error, expected: %v, got: %v", knn.ErrInvalidDocument, result.Errors[1])
+	}
+	doc, err := index.Get("doc_1")
+	if err != nil {
+		t.Fatalf("unable to get document due: %v", err)
+	}
+	expMetadata := map[string]interface{}{"label": "cat"}
+	if !reflect.DeepEqual(expMetadata, doc.(knn.MetadataDocument).GetMetadata()) {
+		t.Fatalf("unexpected metadata, expected: %v, got: %v", expMetadata, doc.(knn.MetadataDocument).GetMetadata())
+	}
+}
+
+func TestImportCSV(t *testing.T) {
+	testCases := []struct {
+		Name         string
+		Input        string
+		Options      knnio.Options
+		ExpErrNil    bool
+		ExpNumAdded  int
+		ExpNumFailed int
+		ExpDocs      []*knn.BasicDocument
+	}{
+		{
+			Name:         "Test Vector Column",
+			Input:        "id,vector\ndoc_1,1 2 3\ndoc_2,1 2\ndoc_3,  4 5   6 \n",
+			ExpErrNil:    true,
+			ExpNumAdded:  2,
+			ExpNumFailed: 1,
+			ExpDocs: []*knn.BasicDocument{
+				{ID: "doc_1", Vector: []float64{1, 2, 3}},
+				{ID: "doc_3", Vector: []float64{4, 5, 6}},
+			},
+		},
+		{
+			Name:  "Test Vector Columns & Metadata",
+			Input: "label;key;x;y;z\ncat;doc_1;1;2;3\ndog;doc_2;4;x;6\nbird;doc_3\nfish;doc_4;7;8;9\n",
+			Options: knnio.Options{
+				IDField:        "key",
+				VectorFields:   []string{"x", "y", "z"},
+				MetadataFields: []string{"label"},
+				Comma:          ';',
+			},
+			ExpErrNil:    true,
+			ExpNumAdded:  2,
+			ExpNumFailed: 2,
+			ExpDocs: []*knn.BasicDocument{
+				{ID: "doc_1", Vector: []float64{1, 2, 3}, Metadata: map[string]interface{}{"label": "cat"}},
+				{ID: "doc_4", Vector: []float64{7, 8, 9}, Metadata: map[string]interface{}{"label": "fish"}},
+			},
+		},
+		{
+			Name:         "Test Invalid Quote",
+			Input:        "id,vector\ndoc_1,1 2 3\ndoc_2,\"1 \"2 3\ndoc_3,4 5 6\n",
+			ExpErrNil:    true,
+			ExpNumAdded:  2,
+			ExpNumFailed: 1,
+		},
+		{
+			Name:      "Test Missing Column",
+			Input:     "id,vec\ndoc_1,1 2 3\n",
+			ExpErrNil: false,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			index := newImportIndex(t)
+			result, err := knnio.ImportCSV(index, strings.NewReader(testCase.Input), testCase.Options)
+			if testCase.ExpErrNil != (err == nil) {
+				t.Fatalf("unexpected error, expected nil: %v, got: %v", testCase.ExpErrNil, err)
+			}
+			if result.NumAdded != testCase.ExpNumAdded || result.NumFailed != testCase.ExpNumFailed {
+				t.Fatalf("unexpected result, expected: %v/%v, got: %v/%v (%v)",
+					testCase.ExpNumAdded, testCase.ExpNumFailed, result.NumAdded, result.NumFailed, result.Errors)
+			}
+			for _, expDoc := range testCase.ExpDocs {
+				doc, err := index.Get(expDoc.ID)
+				if err != nil {
+					t.Fatalf("unable to get document %v due: %v", expDoc.ID, err)
+				}
+				if !reflect.DeepEqual(expDoc, doc) {
+					t.Fatalf("unexpected document, expected: %+v, got: %+v", expDoc, doc)
+				}
+			}
+		})
+	}
+}
+
+func TestImportMemoryLimit(t *testing.T) {
+	var buf bytes.Buffer
+	for i := 0; i < 100; i++ {
+		buf.WriteString(`{"id": "doc_` + strings.Repeat("x", i) + `", "vector": [1, 2, 3]}` + "\n")
+	}
+	index := newIndex(t, knn.Configs{VectorDimension: 3, NumHashTable: 2, NumHyperplane: 2, SlotSize: 5, MemoryLimit: 4096})
+	hook := &mockHook{}
+	index.RegisterHook(hook)
+	result, err := knnio.ImportJSONL(index, &buf, knnio.Options{BatchSize: 10})
+	if !errors.Is(err, knn.ErrMemoryLimit) {
+		t.Fatalf("unexpected error, expected: %v, got: %v", knn.ErrMemoryLimit, err)
+	}
+	if result.NumAdded != index.Len() {
+		t.Fatalf("unexpected number of added documents, expected: %v, got: %v", index.Len(), result.NumAdded)
+	}
+	// every document must be added only once
+	for _, event := range hook.events {
+		if event.Op != knn.OpAdd {
+			t.Fatalf("unexpected event: %v %v", event.Op, event.ID)
+		}
+	}
+	if len(hook.events) != result.NumAdded {
+		t.Fatalf("unexpected number of events, expected: %v, got: %v", result.NumAdded, len(hook.events))
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	testCases := []struct {
+		Name    string
+		Export  func(index *knn.KNN, buf *bytes.Buffer, opts knnio.Options) error
+		Import  func(index *knn.KNN, buf *bytes.Buffer, opts knnio.Options) (knnio.ImportResult, error)
+		Options knnio.Options
+	}{
+		{
+			Name: "Test JSONL",
+			Export: func(index *knn.KNN, buf *bytes.Buffer, opts knnio.Options) error {
+				return knnio.ExportJSONL(index, buf, opts)
+			},
+			Import: func(index *knn.KNN, buf *bytes.Buffer, opts knnio.Options) (knnio.ImportResult, error) {
+				return knnio.ImportJSONL(index, buf, opts)
+			},
+			Options: knnio.Options{IDField: "key", VectorField: "embedding", MetadataField: "attrs"},
+		},
+		{
+			Name: "Test CSV",
+			Export: func(index *knn.KNN, buf *bytes.Buffer, opts knnio.Options) error {
+				return knnio.ExportCSV(index, buf, opts)
+			},
+			Import: func(index *knn.KNN, buf *bytes.Buffer, opts knnio.Options) (knnio.ImportResult, error) {
+				return knnio.ImportCSV(index, buf, opts)
+			},
+			Options: knnio.Options{VectorSeparator: "|", MetadataFields: []string{"label"}},
+		},
+		{
+			Name: "Test CSV Vector Columns",
+			Export: func(index *knn.KNN, buf *bytes.Buffer, opts knnio.Options) error {
+				return knnio.ExportCSV(index, buf, opts)
+			},
+			Import: func(index *knn.KNN, buf *bytes.Buffer, opts knnio.Options) (knnio.ImportResult, error) {
+				return knnio.ImportCSV(index, buf, opts)
+			},
+			Options: knnio.Options{VectorFields: []string{"x", "y", "z"}, MetadataFields: []string{"label"}},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			index := newImportIndex(t)
+			docs := []*knn.BasicDocument{
+				{ID: "doc_1", Vector: []float64{0.1, -2, 3e-7}, Metadata: map[string]interface{}{"label": "cat"}},
+				{ID: "doc_2", Vector: []float64{4, 5, 6}, Metadata: map[string]interface{}{"label": "dog"}},
+				{ID: "doc,3", Vector: []float64{7, 8, 9}, Metadata: map[string]interface{}{"label": "bird \"big\""}},
+			}
+			for _, doc := range docs {
+				index.Add(doc)
+			}
+			var buf bytes.Buffer
+			if err := testCase.Export(index, &buf, testCase.Options); err != nil {
+				t.Fatalf("unable to export due: %v", err)
+			}
+			imported := newImportIndex(t)
+			result, err := testCase.Import(imported, &buf, testCase.Options)
+			if err != nil || result.NumFailed > 0 {
+				t.Fatalf("unable to import due: %v (%v)", err, result.Errors)
+			}
+			if imported.Len() != len(docs) {
+				t.Fatalf("unexpected number of documents, expected: %v, got: %v", len(docs), imported.Len())
+			}
+			for _, expDoc := range docs {
+				doc, err := imported.Get(expDoc.ID)
+				if err != nil {
+					t.Fatalf("unable to get document %v due: %v", expDoc.ID, err)
+				}
+				if !reflect.DeepEqual(expDoc, doc) {
+					t.Fatalf("unexpected document, expected: %+v, got: %+v", expDoc, doc)
+				}
+			}
+		})
+	}
+}
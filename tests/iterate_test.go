@@ -0,0 +1,101 @@
+package test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/riandyrn/go-knn"
+)
+
+func TestIterate(t *testing.T) {
+	// prepare indexes
+	n := 1000
+	dim := 10
+	configs := knn.Configs{
+		VectorDimension: dim,
+		NumHashTable:    2,
+		NumHyperplane:   4,
+		SlotSize:        5,
+	}
+	documents := getMockDocuments(n, dim)
+	expIDs := make([]string, 0, n)
+	for _, document := range documents {
+		expIDs = append(expIDs, document.GetID())
+	}
+	sort.Strings(expIDs)
+
+	testCases := []struct {
+		Name  string
+		Index interface {
+			Add(doc knn.Document) error
+			Len() int
+			Range(fn func(doc knn.Document) bool)
+			IDs(cursor string, limit int) ([]string, error)
+		}
+	}{
+		{
+			Name:  "Single",
+			Index: newIndex(t, configs),
+		},
+		{
+			Name:  "Sharded",
+			Index: newShardedIndex(t, 4, configs),
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			index := testCase.Index
+			for _, document := range documents {
+				index.Add(document)
+			}
+			// check length
+			if index.Len() != n {
+				t.Fatalf("unexpected length, expected: %v, got: %v", n, index.Len())
+			}
+			// check range visits all documents
+			visited := map[string]bool{}
+			index.Range(func(doc knn.Document) bool {
+				visited[doc.GetID()] = true
+				return true
+			})
+			if len(visited) != n {
+				t.Fatalf("unexpected number of visited documents, expected: %v, got: %v", n, len(visited))
+			}
+			// check range could be stopped
+			count := 0
+			index.Range(func(doc knn.Document) bool {
+				count++
+				return count < 10
+			})
+			if count != 10 {
+				t.Fatalf("unexpected number of visited documents, expected: %v, got: %v", 10, count)
+			}
+			// check pagination
+			ids := make([]string, 0, n)
+			cursor := ""
+			limit := 64
+			for {
+				page, err := index.IDs(cursor, limit)
+				if err != nil {
+					t.Fatalf("unable to get ids due: %v", err)
+				}
+				ids = append(ids, page...)
+				if len(page) < limit {
+					break
+				}
+				cursor = page[len(page)-1]
+			}
+			if len(ids) != n {
+				t.Fatalf("unexpected number of ids, expected: %v, got: %v", n, len(ids))
+			}
+			for i := range ids {
+				if ids[i] != expIDs[i] {
+					t.Fatalf("unexpected id at %v, expected: %v, got: %v", i, expIDs[i], ids[i])
+				}
+			}
+			if _, err := index.IDs("", 0); err == nil {
+				t.Fatalf("expecting error on invalid limit")
+			}
+		})
+	}
+}
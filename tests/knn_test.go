@@ -1,8 +1,11 @@
 package test
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"math/rand"
+	"sort"
 	"strconv"
 	"sync"
 	"testing"
@@ -39,7 +42,7 @@ func TestAdd(t *testing.T) {
 	}
 	for _, testCase := range testCases {
 		t.Run(testCase.Name, func(t *testing.T) {
-			knn := knn.NewKNN(knn.Configs{
+			knn := newIndex(t, knn.Configs{
 				VectorDimension: 3,
 				NumHashTable:    2,
 				NumHyperplane:   3,
@@ -64,7 +67,7 @@ func TestQuery(t *testing.T) {
 		newMockDoc("doc_6", []float64{0, 3, 1, 0, 0}),
 	}
 	// initialize knn index
-	knn := knn.NewKNN(knn.Configs{
+	knn := newIndex(t, knn.Configs{
 		VectorDimension: 5,
 		NumHashTable:    3,
 		NumHyperplane:   2,
@@ -121,7 +124,7 @@ func TestDelete(t *testing.T) {
 				newMockDoc("doc_2", getRandomVector(dim)),
 			},
 			DocID:           "doc_3",
-			ExpDeleteErrNil: true,
+			ExpDeleteErrNil: false,
 		},
 		{
 			Name: "Test Delete Existing Document",
@@ -133,10 +136,12 @@ func TestDelete(t *testing.T) {
 			ExpDeleteErrNil: true,
 		},
 	}
+	// deleted document must not be found
+	errNotFound := knn.ErrNotFound
 	for _, testCase := range testCases {
 		t.Run(testCase.Name, func(t *testing.T) {
 			// initialize knn index
-			knn := knn.NewKNN(knn.Configs{
+			knn := newIndex(t, knn.Configs{
 				VectorDimension: dim,
 				NumHashTable:    1,
 				NumHyperplane:   2,
@@ -156,12 +161,68 @@ func TestDelete(t *testing.T) {
 			}
 			// try to get the deleted doc
 			doc, err := knn.Get(testCase.DocID)
-			if err != nil {
-				t.Fatalf("unexpected error when fetching document from index for testcase: %+v, got: %v", testCase, err)
-			}
 			if doc != nil {
 				t.Fatalf("document %v still found on index", doc.GetID())
 			}
+			if !errors.Is(err, errNotFound) {
+				t.Fatalf("unexpected error when fetching document from index for testcase: %+v, got: %v", testCase, err)
+			}
+		})
+	}
+}
+
+func TestDeleteMutatedVector(t *testing.T) {
+	testCases := []struct {
+		Name   string
+		Remove func(index *knn.KNN) error
+	}{
+		{
+			Name:   "Test Delete",
+			Remove: func(index *knn.KNN) error { return index.Delete("doc_1") },
+		},
+		{
+			Name:   "Test Replace",
+			Remove: func(index *knn.KNN) error { return index.Add(newMockDoc("doc_1", []float64{-100, 100})) },
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			numTables := 4
+			index := newIndex(t, knn.Configs{
+				VectorDimension: 2,
+				NumHashTable:    numTables,
+				NumHyperplane:   4,
+				SlotSize:        1,
+			})
+			doc := newMockDoc("doc_1", []float64{0, 0})
+			if err := index.Add(doc); err != nil {
+				t.Fatalf("unable to add document due: %v", err)
+			}
+			// the document is mutated by the caller after being added,
+			// it must still be removed from its original buckets
+			doc.vector = []float64{100, 100}
+			if err := testCase.Remove(index); err != nil {
+				t.Fatalf("unable to remove document due: %v", err)
+			}
+			// the new document reuses handle of the removed one, it
+			// must not be found in the buckets of the original vector
+			if err := index.Add(newMockDoc("doc_2", []float64{100, -100})); err != nil {
+				t.Fatalf("unable to add document due: %v", err)
+			}
+			resultDocs, err := index.Query([]float64{0, 0}, 1)
+			if err != nil {
+				t.Fatalf("unable to query due: %v", err)
+			}
+			if len(resultDocs) > 0 {
+				t.Fatalf("unexpected result, got: %v", resultDocs[0].Document.GetID())
+			}
+			numBuckets := 0
+			for _, table := range index.Stats().Tables {
+				numBuckets += table.NumBuckets
+			}
+			if numBuckets != numTables*index.Len() {
+				t.Fatalf("unexpected number of buckets, expected: %v, got: %v", numTables*index.Len(), numBuckets)
+			}
 		})
 	}
 }
@@ -172,7 +233,7 @@ func TestConcurrentQueries(t *testing.T) {
 	dim := 100
 	documents := getMockDocuments(n, dim)
 	// prepare knn index
-	knn := knn.NewKNN(knn.Configs{
+	knn := newIndex(t, knn.Configs{
 		VectorDimension: dim,
 		NumHashTable:    10,
 		NumHyperplane:   10,
@@ -203,7 +264,7 @@ func TestConcurrentRWOps(t *testing.T) {
 	dim := 100
 	documents := getMockDocuments(n, dim)
 	// prepare knn index
-	knn := knn.NewKNN(knn.Configs{
+	knn := newIndex(t, knn.Configs{
 		VectorDimension: dim,
 		NumHashTable:    10,
 		NumHyperplane:   10,
@@ -240,3 +301,116 @@ func TestConcurrentRWOps(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+func TestNumWorkers(t *testing.T) {
+	// prepare documents
+	n := 1000
+	dim := 20
+	documents := getMockDocuments(n, dim)
+	configs := knn.Configs{
+		VectorDimension: dim,
+		NumHashTable:    8,
+		NumHyperplane:   4,
+		SlotSize:        5,
+	}
+	// index with worker pool must returns the same result
+	// as sequential index
+	sequential := newIndex(t, configs)
+	configs.NumWorkers = 4
+	parallel := newIndex(t, configs)
+	defer parallel.Close()
+	for i, document := range documents {
+		sequential.Add(document)
+		parallel.Add(document)
+		if i%10 == 0 {
+			sequential.Delete(document.GetID())
+			parallel.Delete(document.GetID())
+		}
+	}
+	for i := 0; i < 20; i++ {
+		queryVector := getRandomVector(dim)
+		expDocs, _ := sequential.Query(queryVector, 10)
+		resultDocs, _ := parallel.Query(queryVector, 10)
+		if len(resultDocs) != len(expDocs) {
+			t.Fatalf("unexpected number of result, expected: %v, got: %v", len(expDocs), len(resultDocs))
+		}
+		for j := range expDocs {
+			if resultDocs[j].Distance != expDocs[j].Distance {
+				t.Fatalf("unexpected distance at %v, expected: %v, got: %v", j, expDocs[j].Distance, resultDocs[j].Distance)
+			}
+		}
+	}
+}
+
+func TestAddReplace(t *testing.T) {
+	// initialize knn index
+	index := newIndex(t, knn.Configs{
+		VectorDimension: 5,
+		NumHashTable:    3,
+		NumHyperplane:   2,
+		SlotSize:        5,
+	})
+	oldVector := []float64{0, 0, 0, 0, 0}
+	newVector := []float64{8, 6, 5, 4, 9}
+	index.Add(newMockDoc("doc_1", oldVector))
+	index.Add(newMockDoc("doc_1", newVector))
+	// the old vector must no longer be indexed
+	resultDocs, err := index.Query(oldVector, 10)
+	if err != nil {
+		t.Fatalf("unexpected error, err: %v", err)
+	}
+	for _, resultDoc := range resultDocs {
+		if resultDoc.Distance == 0 {
+			t.Fatalf("replaced document is still found by its old vector")
+		}
+	}
+	// the new vector must be indexed exactly once
+	resultDocs, err = index.Query(newVector, 10)
+	if err != nil {
+		t.Fatalf("unexpected error, err: %v", err)
+	}
+	if len(resultDocs) != 1 || resultDocs[0].Distance != 0 {
+		t.Fatalf("unexpected result: %+v", resultDocs)
+	}
+}
+
+func TestQueryTopK(t *testing.T) {
+	// prepare documents
+	n := 2000
+	dim := 10
+	documents := getMockDocuments(n, dim)
+	index := newIndex(t, knn.Configs{
+		VectorDimension: dim,
+		NumHashTable:    1,
+		NumHyperplane:   1,
+		SlotSize:        10000,
+	})
+	for _, document := range documents {
+		index.Add(document)
+	}
+	// with single huge slot all documents are candidates, so
+	// the result must be the exact k nearest neighbors
+	queryVector := getRandomVector(dim)
+	k := 10
+	resultDocs, err := index.Query(queryVector, k)
+	if err != nil {
+		t.Fatalf("unexpected error, err: %v", err)
+	}
+	distances := make([]float64, 0, n)
+	for _, document := range documents {
+		sum := 0.0
+		for i, v := range document.GetVector() {
+			sum += (v - queryVector[i]) * (v - queryVector[i])
+		}
+		distances = append(distances, math.Sqrt(sum))
+	}
+	sort.Float64s(distances)
+	if len(resultDocs) != k {
+		t.Fatalf("unexpected number of result, expected: %v, got: %v", k, len(resultDocs))
+	}
+	for i := range resultDocs {
+		if math.Abs(resultDocs[i].Distance-distances[i]) > 1e-9 {
+			t.Fatalf("unexpected distance at %v, expected: %v, got: %v", i, distances[i], resultDocs[i].Distance)
+		}
+	}
+}
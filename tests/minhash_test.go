@@ -0,0 +1,125 @@
+package test
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/riandyrn/go-knn"
+)
+
+func TestMinHasherEstimateSimilarity(t *testing.T) {
+	// prepare sets with jaccard similarity 0.5
+	set1 := make([]string, 0, 150)
+	set2 := make([]string, 0, 150)
+	for i := 0; i < 200; i++ {
+		elem := strings.Repeat("x", i)
+		if i < 150 {
+			set1 = append(set1, elem)
+		}
+		if i >= 50 {
+			set2 = append(set2, elem)
+		}
+	}
+	hasher := knn.NewMinHasher(256, 1)
+	similarity := knn.EstimateSimilarity(hasher.Signature(set1), hasher.Signature(set2))
+	if math.Abs(similarity-0.5) > 0.1 {
+		t.Fatalf("estimated similarity is too far, expected: 0.5, got: %v", similarity)
+	}
+}
+
+func TestNewMinHashKNN(t *testing.T) {
+	testCases := []struct {
+		Name      string
+		Configs   knn.MinHashConfigs
+		ExpErrNil bool
+	}{
+		{
+			Name:      "Test Zero Bands",
+			Configs:   knn.MinHashConfigs{NumBand: 0, NumRow: 2},
+			ExpErrNil: false,
+		},
+		{
+			Name:      "Test Zero Rows",
+			Configs:   knn.MinHashConfigs{NumBand: 20, NumRow: 0},
+			ExpErrNil: false,
+		},
+		{
+			Name:      "Test Negative Rows",
+			Configs:   knn.MinHashConfigs{NumBand: 20, NumRow: -1},
+			ExpErrNil: false,
+		},
+		{
+			Name:      "Test Normal Configs",
+			Configs:   knn.MinHashConfigs{NumBand: 20, NumRow: 2},
+			ExpErrNil: true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			_, err := knn.NewMinHashKNN(testCase.Configs)
+			if (err == nil) != testCase.ExpErrNil {
+				t.Fatalf("unexpected error for case: %+v, err: %v", testCase, err)
+			}
+		})
+	}
+}
+
+func TestMinHashQuery(t *testing.T) {
+	// prepare documents
+	docs := []*mockSetDoc{
+		newMockSetDoc("doc_1", strings.Fields("the quick brown fox jumps over the lazy dog")),
+		newMockSetDoc("doc_2", strings.Fields("the quick brown fox jumps over the lazy cat")),
+		newMockSetDoc("doc_3", strings.Fields("a quick brown fox jumps over the lazy dog")),
+		newMockSetDoc("doc_4", strings.Fields("lorem ipsum dolor sit amet")),
+	}
+	// initialize index
+	index, err := knn.NewMinHashKNN(knn.MinHashConfigs{
+		NumBand: 20,
+		NumRow:  2,
+	})
+	if err != nil {
+		t.Fatalf("unable to create index due: %v", err)
+	}
+	for _, doc := range docs {
+		if err := index.Add(doc); err != nil {
+			t.Fatalf("unexpected error, err: %v", err)
+		}
+	}
+	// execute top-k query
+	resultDocs, err := index.Query(docs[0].GetSet(), 2)
+	if err != nil {
+		t.Fatalf("unexpected error, err: %v", err)
+	}
+	if len(resultDocs) != 2 || resultDocs[0].Document.GetID() != "doc_1" || resultDocs[0].Similarity != 1 {
+		t.Fatalf("unexpected result: %+v", resultDocs)
+	}
+	// execute threshold query
+	resultDocs, err = index.QueryThreshold(docs[0].GetSet(), 0.7)
+	if err != nil {
+		t.Fatalf("unexpected error, err: %v", err)
+	}
+	idMap := map[string]bool{}
+	for _, resultDoc := range resultDocs {
+		idMap[resultDoc.Document.GetID()] = true
+	}
+	for _, id := range []string{"doc_1", "doc_2", "doc_3"} {
+		if !idMap[id] {
+			t.Fatalf("document with id: %v is not found on result", id)
+		}
+	}
+	if idMap["doc_4"] {
+		t.Fatalf("document with id: doc_4 should not be found on result")
+	}
+	// delete document & make sure it is no longer returned
+	if err := index.Delete("doc_1"); err != nil {
+		t.Fatalf("unexpected error, err: %v", err)
+	}
+	resultDocs, err = index.QueryThreshold(docs[0].GetSet(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error, err: %v", err)
+	}
+	if len(resultDocs) != 0 {
+		t.Fatalf("deleted document still found on result: %+v", resultDocs)
+	}
+}
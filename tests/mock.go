@@ -3,10 +3,29 @@ package test
 import (
 	"fmt"
 	"math/rand"
+	"testing"
+	"time"
 
 	"github.com/riandyrn/go-knn"
 )
 
+// newIndex returns KNN index with `configs`, it fails the
+// test when the configs are invalid
+func newIndex(tb testing.TB, configs knn.Configs) *knn.KNN {
+	if err := configs.Validate(); err != nil {
+		tb.Fatalf("invalid configs due: %v", err)
+	}
+	return knn.NewKNN(configs)
+}
+
+// newShardedIndex is like newIndex but for ShardedKNN
+func newShardedIndex(tb testing.TB, numShards int, configs knn.Configs) *knn.ShardedKNN {
+	if err := configs.Validate(); err != nil {
+		tb.Fatalf("invalid configs due: %v", err)
+	}
+	return knn.NewShardedKNN(numShards, configs)
+}
+
 func newMockDoc(id string, vector []float64) *mockDoc {
 	return &mockDoc{
 		id:     id,
@@ -40,3 +59,67 @@ func getMockDocuments(n, dim int) []knn.Document {
 	}
 	return documents
 }
+
+func newMockBinaryDoc(id string, bits []uint64) *mockBinaryDoc {
+	return &mockBinaryDoc{
+		id:   id,
+		bits: bits,
+	}
+}
+
+type mockBinaryDoc struct {
+	id   string
+	bits []uint64
+}
+
+func (d *mockBinaryDoc) GetID() string { return d.id }
+
+func (d *mockBinaryDoc) GetBits() []uint64 { return d.bits }
+
+func newMockSetDoc(id string, set []string) *mockSetDoc {
+	return &mockSetDoc{
+		id:  id,
+		set: set,
+	}
+}
+
+type mockSetDoc struct {
+	id  string
+	set []string
+}
+
+func (d *mockSetDoc) GetID() string { return d.id }
+
+func (d *mockSetDoc) GetSet() []string { return d.set }
+
+func newMockSparseDoc(id string, indices []int, values []float64) *mockSparseDoc {
+	return &mockSparseDoc{
+		id:      id,
+		indices: indices,
+		values:  values,
+	}
+}
+
+type mockSparseDoc struct {
+	id      string
+	indices []int
+	values  []float64
+}
+
+func (d *mockSparseDoc) GetID() string { return d.id }
+
+func (d *mockSparseDoc) GetSparseVector() ([]int, []float64) { return d.indices, d.values }
+
+func newMockExpiringDoc(id string, vector []float64, expiry time.Time) *mockExpiringDoc {
+	return &mockExpiringDoc{
+		mockDoc: mockDoc{id: id, vector: vector},
+		expiry:  expiry,
+	}
+}
+
+type mockExpiringDoc struct {
+	mockDoc
+	expiry time.Time
+}
+
+func (d *mockExpiringDoc) GetExpiry() time.Time { return d.expiry }
@@ -0,0 +1,79 @@
+package test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/riandyrn/go-knn"
+)
+
+func TestSaveLoad(t *testing.T) {
+	// prepare index with dense, sparse & expiring documents
+	dim := 10
+	index := newReplicaIndex(t, dim)
+	for _, doc := range getMockDocuments(100, dim) {
+		index.Add(doc)
+	}
+	index.Add(&knn.BasicDocument{
+		ID:       "doc_meta",
+		Vector:   getRandomVector(dim),
+		Metadata: map[string]interface{}{"label": "cat"},
+	})
+	index.AddSparse(newMockSparseDoc("sparse_1", []int{1, 5}, []float64{0.5, 1}))
+	index.Add(newMockExpiringDoc("doc_expired", getRandomVector(dim), time.Now().Add(-time.Second)))
+	index.Add(newMockExpiringDoc("doc_expiring", getRandomVector(dim), time.Now().Add(time.Hour)))
+
+	// save then load into new index
+	var buf bytes.Buffer
+	if err := index.Save(&buf); err != nil {
+		t.Fatalf("unable to save index due: %v", err)
+	}
+	loaded := newReplicaIndex(t, dim)
+	if err := loaded.Load(&buf); err != nil {
+		t.Fatalf("unable to load index due: %v", err)
+	}
+	// the expired document is not saved
+	index.Delete("doc_expired")
+	checkReplica(t, index, loaded)
+	doc, err := loaded.Get("doc_meta")
+	if err != nil {
+		t.Fatalf("unable to get document due: %v", err)
+	}
+	metadata := doc.(knn.MetadataDocument).GetMetadata()
+	if !reflect.DeepEqual(metadata, map[string]interface{}{"label": "cat"}) {
+		t.Fatalf("unexpected metadata, got: %v", metadata)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	testCases := []struct {
+		Name   string
+		Input  string
+		ExpLen int
+	}{
+		{
+			Name:   "Test Malformed Line",
+			Input:  "{\"id\":\"doc_1\",\"vector\":[1,2]}\n{\"id\":",
+			ExpLen: 1,
+		},
+		{
+			Name:   "Test Dimension Mismatch",
+			Input:  "{\"id\":\"doc_1\",\"vector\":[1,2]}\n{\"id\":\"doc_2\",\"vector\":[1]}\n",
+			ExpLen: 1,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			index := newReplicaIndex(t, 2)
+			if err := index.Load(strings.NewReader(testCase.Input)); err == nil {
+				t.Fatalf("expected error on invalid input")
+			}
+			if index.Len() != testCase.ExpLen {
+				t.Fatalf("unexpected number of documents, expected: %v, got: %v", testCase.ExpLen, index.Len())
+			}
+		})
+	}
+}
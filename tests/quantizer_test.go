@@ -0,0 +1,183 @@
+package test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/riandyrn/go-knn"
+)
+
+func TestTrainScalarQuantizer(t *testing.T) {
+	testCases := []struct {
+		Name      string
+		Vectors   [][]float64
+		ExpErrNil bool
+	}{
+		{
+			Name:      "Test Empty Vectors",
+			Vectors:   nil,
+			ExpErrNil: false,
+		},
+		{
+			Name:      "Test Mismatch Dimension",
+			Vectors:   [][]float64{{1, 2, 3}, {1, 2}},
+			ExpErrNil: false,
+		},
+		{
+			Name:      "Test Normal Vectors",
+			Vectors:   [][]float64{{1, 2, 3}, {-1, 5, 3}},
+			ExpErrNil: true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			_, err := knn.TrainScalarQuantizer(testCase.Vectors)
+			if (err == nil) != testCase.ExpErrNil {
+				t.Fatalf("unexpected error for case: %+v, err: %v", testCase, err)
+			}
+		})
+	}
+}
+
+func TestScalarQuantizerEncodeDecode(t *testing.T) {
+	// prepare quantizer
+	dim := 100
+	vectors := make([][]float64, 0, 1000)
+	for i := 0; i < cap(vectors); i++ {
+		vectors = append(vectors, getRandomVector(dim))
+	}
+	quantizer, err := knn.TrainScalarQuantizer(vectors)
+	if err != nil {
+		t.Fatalf("unable to train quantizer due: %v", err)
+	}
+	// the decoded vector must be within half step of the
+	// original vector on every dimension
+	for _, vector := range vectors[:10] {
+		decoded := quantizer.Decode(quantizer.Encode(vector))
+		for i := range vector {
+			if math.Abs(decoded[i]-vector[i]) > 0.1 {
+				t.Fatalf("decoded value is too far, expected: %v, got: %v", vector[i], decoded[i])
+			}
+		}
+	}
+}
+
+func TestQuantizedQuery(t *testing.T) {
+	// prepare documents
+	docs := []*mockDoc{
+		newMockDoc("doc_1", []float64{0, 0, 0, 0, 0}),
+		newMockDoc("doc_2", []float64{0, 1, 0, 0, 0}),
+		newMockDoc("doc_3", []float64{8, 6, 5, 4, 9}),
+		newMockDoc("doc_4", []float64{0, 0, 2, 0, 1}),
+		newMockDoc("doc_5", []float64{1, 2, 0, 0, 3}),
+		newMockDoc("doc_6", []float64{0, 3, 1, 0, 0}),
+	}
+	vectors := make([][]float64, 0, len(docs))
+	for _, doc := range docs {
+		vectors = append(vectors, doc.GetVector())
+	}
+	quantizer, err := knn.TrainScalarQuantizer(vectors)
+	if err != nil {
+		t.Fatalf("unable to train quantizer due: %v", err)
+	}
+	for _, rescoreSize := range []int{0, 3} {
+		// initialize knn index
+		index := newIndex(t, knn.Configs{
+			VectorDimension: 5,
+			NumHashTable:    3,
+			NumHyperplane:   2,
+			SlotSize:        5,
+			Quantizer:       quantizer,
+			RescoreSize:     rescoreSize,
+		})
+		for _, doc := range docs {
+			index.Add(doc)
+		}
+		// execute query
+		resultDocs, err := index.Query(docs[0].GetVector(), 2)
+		if err != nil {
+			t.Fatalf("unexpected error, err: %v", err)
+		}
+		if len(resultDocs) != 2 {
+			t.Fatalf("unexpected number of result, expected: 2, got: %v", len(resultDocs))
+		}
+		if resultDocs[0].Document.GetID() != "doc_1" || resultDocs[1].Document.GetID() != "doc_2" {
+			t.Fatalf("unexpected result for rescore size %v: %v, %v", rescoreSize, resultDocs[0].Document.GetID(), resultDocs[1].Document.GetID())
+		}
+		// exact rescoring must returns exact distance
+		if rescoreSize > 0 && resultDocs[1].Distance != 1 {
+			t.Fatalf("unexpected rescored distance, expected: 1, got: %v", resultDocs[1].Distance)
+		}
+	}
+}
+
+func TestQuantizerDimension(t *testing.T) {
+	quantizer, err := knn.TrainScalarQuantizer([][]float64{{0, 0, 0, 0}, {1, 1, 1, 1}})
+	if err != nil {
+		t.Fatalf("unable to train quantizer due: %v", err)
+	}
+	err = knn.Configs{
+		VectorDimension: 2,
+		NumHashTable:    1,
+		NumHyperplane:   1,
+		SlotSize:        5,
+		Quantizer:       quantizer,
+	}.Validate()
+	if !errors.Is(err, knn.ErrDimensionMismatch) {
+		t.Fatalf("unexpected error, expected: %v, got: %v", knn.ErrDimensionMismatch, err)
+	}
+}
+
+func TestQuantizedMemory(t *testing.T) {
+	// prepare documents
+	n := 100
+	dim := 64
+	docs := getMockDocuments(n, dim)
+	vectors := make([][]float64, 0, n)
+	for _, doc := range docs {
+		vectors = append(vectors, doc.GetVector())
+	}
+	quantizer, err := knn.TrainScalarQuantizer(vectors)
+	if err != nil {
+		t.Fatalf("unable to train quantizer due: %v", err)
+	}
+	configs := knn.Configs{
+		VectorDimension: dim,
+		NumHashTable:    2,
+		NumHyperplane:   2,
+		SlotSize:        5,
+	}
+	plain := newIndex(t, configs)
+	configs.Quantizer = quantizer
+	quantized := newIndex(t, configs)
+	for _, doc := range docs {
+		plain.Add(doc)
+		quantized.Add(doc)
+	}
+	quantized.Add(&knn.BasicDocument{
+		ID:       "doc_meta",
+		Vector:   vectors[0],
+		Metadata: map[string]interface{}{"label": "cat"},
+	})
+	// without rescoring only the codes are kept, so the
+	// vectors take roughly 1/8 of the memory
+	plainBytes, quantizedBytes := plain.Stats().VectorBytes, quantized.Stats().VectorBytes
+	if quantizedBytes*4 > plainBytes {
+		t.Fatalf("vectors memory is not reduced, plain: %v, quantized: %v", plainBytes, quantizedBytes)
+	}
+	// the returned document holds the decoded vector & metadata
+	doc, err := quantized.Get("doc_meta")
+	if err != nil {
+		t.Fatalf("unable to get document due: %v", err)
+	}
+	for i, v := range doc.GetVector() {
+		if math.Abs(v-vectors[0][i]) > 0.1 {
+			t.Fatalf("decoded value is too far, expected: %v, got: %v", vectors[0][i], v)
+		}
+	}
+	metadata := doc.(knn.MetadataDocument).GetMetadata()
+	if metadata["label"] != "cat" {
+		t.Fatalf("unexpected metadata, got: %v", metadata)
+	}
+}
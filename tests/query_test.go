@@ -0,0 +1,185 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/riandyrn/go-knn"
+)
+
+func TestAddBatch(t *testing.T) {
+	dim := 5
+	testCases := []struct {
+		Name      string
+		InputDocs []knn.Document
+		ExpErrNil bool
+		ExpLen    int
+	}{
+		{
+			Name:      "Test Valid Batch",
+			InputDocs: getMockDocuments(10, dim),
+			ExpErrNil: true,
+			ExpLen:    10,
+		},
+		{
+			Name:      "Test Empty Batch",
+			InputDocs: nil,
+			ExpErrNil: true,
+			ExpLen:    0,
+		},
+		{
+			Name: "Test Invalid Document",
+			InputDocs: []knn.Document{
+				newMockDoc("doc_1", getRandomVector(dim)),
+				newMockDoc("doc_2", getRandomVector(dim+1)),
+			},
+			ExpErrNil: false,
+			ExpLen:    0,
+		},
+		{
+			Name: "Test Nil Document",
+			InputDocs: []knn.Document{
+				newMockDoc("doc_1", getRandomVector(dim)),
+				nil,
+			},
+			ExpErrNil: false,
+			ExpLen:    0,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			index := newIndex(t, knn.Configs{
+				VectorDimension: dim,
+				NumHashTable:    2,
+				NumHyperplane:   2,
+				SlotSize:        5,
+			})
+			err := index.AddBatch(testCase.InputDocs)
+			if testCase.ExpErrNil != (err == nil) {
+				t.Fatalf("unexpected error, expected nil: %v, got: %v", testCase.ExpErrNil, err)
+			}
+			if index.Len() != testCase.ExpLen {
+				t.Fatalf("unexpected number of documents, expected: %v, got: %v", testCase.ExpLen, index.Len())
+			}
+		})
+	}
+}
+
+func TestQueryByID(t *testing.T) {
+	// prepare index, use single hyperplane with large slot
+	// so all documents land on the same bucket
+	index := newIndex(t, knn.Configs{
+		VectorDimension: 2,
+		NumHashTable:    1,
+		NumHyperplane:   1,
+		SlotSize:        1000000,
+	})
+	index.Add(newMockDoc("doc_1", []float64{0, 0}))
+	index.Add(newMockDoc("doc_2", []float64{0, 1}))
+	index.Add(newMockDoc("doc_3", []float64{0, 3}))
+	index.Add(newMockDoc("doc_4", []float64{0, 7}))
+	// query by id
+	testCases := []struct {
+		Name   string
+		ID     string
+		K      int
+		ExpIDs []string
+		ExpErr error
+	}{
+		{
+			Name:   "Test Existing Document",
+			ID:     "doc_1",
+			K:      2,
+			ExpIDs: []string{"doc_2", "doc_3"},
+		},
+		{
+			Name:   "Test K Larger Than Index",
+			ID:     "doc_3",
+			K:      10,
+			ExpIDs: []string{"doc_2", "doc_1", "doc_4"},
+		},
+		{
+			Name:   "Test Missing Document",
+			ID:     "doc_5",
+			K:      2,
+			ExpErr: knn.ErrNotFound,
+		},
+		{
+			Name:   "Test Invalid K",
+			ID:     "doc_1",
+			K:      0,
+			ExpErr: knn.ErrInvalidK,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			resultDocs, err := index.QueryByID(testCase.ID, testCase.K)
+			if !errors.Is(err, testCase.ExpErr) {
+				t.Fatalf("unexpected error, expected: %v, got: %v", testCase.ExpErr, err)
+			}
+			if len(resultDocs) != len(testCase.ExpIDs) {
+				t.Fatalf("unexpected number of results, expected: %v, got: %v", len(testCase.ExpIDs), len(resultDocs))
+			}
+			for i, resultDoc := range resultDocs {
+				if resultDoc.Document.GetID() != testCase.ExpIDs[i] {
+					t.Fatalf("unexpected result at %v, expected: %v, got: %v", i, testCase.ExpIDs[i], resultDoc.Document.GetID())
+				}
+			}
+		})
+	}
+}
+
+func TestQueryRadius(t *testing.T) {
+	// prepare index
+	index := newIndex(t, knn.Configs{
+		VectorDimension: 2,
+		NumHashTable:    1,
+		NumHyperplane:   1,
+		SlotSize:        1000000,
+	})
+	index.Add(newMockDoc("doc_1", []float64{0, 0}))
+	index.Add(newMockDoc("doc_2", []float64{0, 1}))
+	index.Add(newMockDoc("doc_3", []float64{0, 3}))
+	index.Add(newMockDoc("doc_4", []float64{0, 7}))
+	// query within radius
+	testCases := []struct {
+		Name      string
+		Radius    float64
+		ExpIDs    []string
+		ExpErrNil bool
+	}{
+		{
+			Name:      "Test Zero Radius",
+			Radius:    0,
+			ExpIDs:    []string{"doc_1"},
+			ExpErrNil: true,
+		},
+		{
+			Name:      "Test Inclusive Radius",
+			Radius:    3,
+			ExpIDs:    []string{"doc_1", "doc_2", "doc_3"},
+			ExpErrNil: true,
+		},
+		{
+			Name:      "Test Negative Radius",
+			Radius:    -1,
+			ExpErrNil: false,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			resultDocs, err := index.QueryRadius([]float64{0, 0}, testCase.Radius)
+			if testCase.ExpErrNil != (err == nil) {
+				t.Fatalf("unexpected error, expected nil: %v, got: %v", testCase.ExpErrNil, err)
+			}
+			if len(resultDocs) != len(testCase.ExpIDs) {
+				t.Fatalf("unexpected number of results, expected: %v, got: %v", len(testCase.ExpIDs), len(resultDocs))
+			}
+			for i, resultDoc := range resultDocs {
+				if resultDoc.Document.GetID() != testCase.ExpIDs[i] {
+					t.Fatalf("unexpected result at %v, expected: %v, got: %v", i, testCase.ExpIDs[i], resultDoc.Document.GetID())
+				}
+			}
+		})
+	}
+}
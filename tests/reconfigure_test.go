@@ -0,0 +1,105 @@
+package test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/riandyrn/go-knn"
+)
+
+func TestReconfigure(t *testing.T) {
+	// prepare index
+	n := 2000
+	dim := 20
+	configs := knn.Configs{
+		VectorDimension: dim,
+		NumHashTable:    2,
+		NumHyperplane:   4,
+		SlotSize:        5,
+	}
+	index := newIndex(t, configs)
+	documents := getMockDocuments(n, dim)
+	for _, document := range documents {
+		index.Add(document)
+	}
+	// write to index while reconfiguring
+	added := getMockDocuments(100, dim)
+	for i, document := range added {
+		added[i] = newMockDoc(fmt.Sprintf("added_%v", i), document.GetVector())
+	}
+	deleted := documents[:100]
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := range added {
+			index.Add(added[i])
+			index.Delete(deleted[i].GetID())
+		}
+	}()
+	newConfigs := configs
+	newConfigs.NumHashTable = 8
+	newConfigs.NumHyperplane = 2
+	lastDone, lastTotal := 0, 0
+	err := index.Reconfigure(newConfigs, func(done, total int) {
+		if done <= lastDone || done > total {
+			t.Fatalf("unexpected progress, done: %v, total: %v", done, total)
+		}
+		lastDone, lastTotal = done, total
+	})
+	if err != nil {
+		t.Fatalf("unable to reconfigure index due: %v", err)
+	}
+	if lastDone != lastTotal || lastTotal < n {
+		t.Fatalf("unexpected last progress, done: %v, total: %v", lastDone, lastTotal)
+	}
+	wg.Wait()
+	// check new tables are used
+	stats := index.Stats()
+	if len(stats.Tables) != newConfigs.NumHashTable {
+		t.Fatalf("unexpected number of tables, expected: %v, got: %v", newConfigs.NumHashTable, len(stats.Tables))
+	}
+	if stats.NumDocuments != n {
+		t.Fatalf("unexpected number of documents, expected: %v, got: %v", n, stats.NumDocuments)
+	}
+	// check all documents could be found in new tables
+	for _, document := range append(documents[100:], added...) {
+		resultDocs, _ := index.Query(document.GetVector(), 1)
+		if len(resultDocs) != 1 || resultDocs[0].Document.GetID() != document.GetID() {
+			t.Fatalf("unable to find document %v", document.GetID())
+		}
+	}
+	for _, document := range deleted {
+		resultDocs, _ := index.Query(document.GetVector(), 1)
+		if len(resultDocs) > 0 && resultDocs[0].Document.GetID() == document.GetID() {
+			t.Fatalf("deleted document %v is found", document.GetID())
+		}
+	}
+}
+
+func TestReconfigureInvalid(t *testing.T) {
+	configs := knn.Configs{
+		VectorDimension: 10,
+		NumHashTable:    2,
+		NumHyperplane:   4,
+		SlotSize:        5,
+	}
+	index := newIndex(t, configs)
+	// check invalid configs
+	invalidConfigs := configs
+	invalidConfigs.VectorDimension = 20
+	if err := index.Reconfigure(invalidConfigs, nil); err == nil {
+		t.Fatalf("expecting error on mismatch vector dimension")
+	}
+	invalidConfigs = configs
+	invalidConfigs.NumHashTable = 0
+	if err := index.Reconfigure(invalidConfigs, nil); err == nil {
+		t.Fatalf("expecting error on invalid number of hash tables")
+	}
+	// check closed index
+	index.Close()
+	if err := index.Reconfigure(configs, nil); err == nil {
+		t.Fatalf("expecting error on closed index")
+	}
+}
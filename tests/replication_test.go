@@ -0,0 +1,231 @@
+package test
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/riandyrn/go-knn"
+)
+
+func newReplicaIndex(t *testing.T, dim int) *knn.KNN {
+	return newIndex(t, knn.Configs{
+		VectorDimension: dim,
+		NumHashTable:    2,
+		NumHyperplane:   4,
+		SlotSize:        5,
+	})
+}
+
+// waitSeq waits until `follower` applies mutation `seq`
+func waitSeq(t *testing.T, follower *knn.Follower, seq uint64) {
+	deadline := time.Now().Add(5 * time.Second)
+	for follower.Seq() < seq {
+		if time.Now().After(deadline) {
+			t.Fatalf("follower is not catching up, expected seq: %v, got: %v", seq, follower.Seq())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// waitLen waits until `replica` holds `n` documents, it is needed
+// since all snapshot records have the same sequence number
+func waitLen(t *testing.T, replica *knn.KNN, n int) {
+	deadline := time.Now().Add(5 * time.Second)
+	for replica.Len() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("snapshot is not applied, expected length: %v, got: %v", n, replica.Len())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// checkReplica checks whether `replica` holds the same documents as `index`
+func checkReplica(t *testing.T, index, replica *knn.KNN) {
+	if index.Len() != replica.Len() {
+		t.Fatalf("unexpected number of documents, expected: %v, got: %v", index.Len(), replica.Len())
+	}
+	index.Range(func(doc knn.Document) bool {
+		replicaDoc, err := replica.Get(doc.GetID())
+		if err != nil {
+			t.Fatalf("unable to get document %v due: %v", doc.GetID(), err)
+		}
+		if !reflect.DeepEqual(doc.GetVector(), replicaDoc.GetVector()) {
+			t.Fatalf("unexpected vector of document %v", doc.GetID())
+		}
+		return true
+	})
+	index.RangeSparse(func(doc knn.SparseDocument) bool {
+		replicaDoc, err := replica.GetSparse(doc.GetID())
+		if err != nil {
+			t.Fatalf("unable to get sparse document %v due: %v", doc.GetID(), err)
+		}
+		expIndices, expValues := doc.GetSparseVector()
+		indices, values := replicaDoc.GetSparseVector()
+		if !reflect.DeepEqual(expIndices, indices) || !reflect.DeepEqual(expValues, values) {
+			t.Fatalf("unexpected vector of sparse document %v", doc.GetID())
+		}
+		return true
+	})
+}
+
+func TestReplicationOverConn(t *testing.T) {
+	// prepare leader which already has documents
+	dim := 10
+	index := newReplicaIndex(t, dim)
+	for _, doc := range getMockDocuments(100, dim) {
+		index.Add(doc)
+	}
+	leader := knn.NewLeader(index, 1000)
+	defer leader.Close()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen due: %v", err)
+	}
+	defer ln.Close()
+	go leader.Accept(ln)
+
+	// sync follower from the start
+	replica := newReplicaIndex(t, dim)
+	follower := knn.NewFollower(replica)
+	sync := func() (net.Conn, chan error) {
+		conn, err := net.Dial("tcp", ln.Addr().String())
+		if err != nil {
+			t.Fatalf("unable to connect due: %v", err)
+		}
+		errCh := make(chan error, 1)
+		go func() { errCh <- follower.Sync(conn) }()
+		return conn, errCh
+	}
+	conn, errCh := sync()
+	waitSeq(t, follower, 100)
+	waitLen(t, replica, index.Len())
+	checkReplica(t, index, replica)
+
+	// apply live mutations
+	index.Add(newMockDoc("doc_0", getRandomVector(dim)))
+	index.Delete("doc_1")
+	index.AddSparse(newMockSparseDoc("sparse_1", []int{1, 5}, []float64{0.5, 1}))
+	index.Add(newMockDoc("doc_100", getRandomVector(dim)))
+	waitSeq(t, follower, 104)
+	checkReplica(t, index, replica)
+
+	// disconnect follower then apply more mutations
+	conn.Close()
+	<-errCh
+	index.Delete("doc_2")
+	index.Add(newMockDoc("doc_3", getRandomVector(dim)))
+
+	// resume follower from its last mutation
+	conn, errCh = sync()
+	waitSeq(t, follower, 106)
+	checkReplica(t, index, replica)
+
+	// reset is replicated as well
+	index.Reset()
+	index.Add(newMockDoc("doc_1", getRandomVector(dim)))
+	waitSeq(t, follower, 108)
+	checkReplica(t, index, replica)
+	conn.Close()
+	<-errCh
+}
+
+func TestReplicationIdleDisconnect(t *testing.T) {
+	// prepare idle leader
+	dim := 10
+	index := newReplicaIndex(t, dim)
+	leader := knn.NewLeader(index, 1000)
+	defer leader.Close()
+	index.Add(newMockDoc("doc_1", getRandomVector(dim)))
+	server, client := net.Pipe()
+	defer server.Close()
+	serveErrCh := make(chan error, 1)
+	go func() { serveErrCh <- leader.ServeConn(server) }()
+	if _, err := client.Write([]byte(`{"from_seq": 1}` + "\n")); err != nil {
+		t.Fatalf("unable to send handshake due: %v", err)
+	}
+	// disconnected follower is detected without new mutation
+	client.Close()
+	select {
+	case err := <-serveErrCh:
+		if err != nil {
+			t.Fatalf("unexpected serve error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("disconnected follower is not detected")
+	}
+}
+
+func TestReplicationOverPipe(t *testing.T) {
+	// prepare leader
+	dim := 10
+	index := newReplicaIndex(t, dim)
+	for _, doc := range getMockDocuments(10, dim) {
+		index.Add(doc)
+	}
+	leader := knn.NewLeader(index, 1000)
+	// stream to follower through pipe
+	pr, pw := io.Pipe()
+	serveErrCh := make(chan error, 1)
+	go func() {
+		serveErrCh <- leader.Serve(pw, 0)
+		pw.Close()
+	}()
+	replica := newReplicaIndex(t, dim)
+	follower := knn.NewFollower(replica)
+	applyErrCh := make(chan error, 1)
+	go func() { applyErrCh <- follower.Apply(pr) }()
+
+	index.Delete("doc_5")
+	index.Add(newMockDoc("doc_5", getRandomVector(dim)))
+	waitSeq(t, follower, 12)
+	checkReplica(t, index, replica)
+
+	// closing leader ends the stream
+	leader.Close()
+	if err := <-serveErrCh; err != nil {
+		t.Fatalf("unexpected serve error: %v", err)
+	}
+	if err := <-applyErrCh; err != nil {
+		t.Fatalf("unexpected apply error: %v", err)
+	}
+}
+
+func TestReplicationLogTruncated(t *testing.T) {
+	// prepare leader which only keeps the last 2 mutations
+	dim := 10
+	index := newReplicaIndex(t, dim)
+	leader := knn.NewLeader(index, 2)
+	defer leader.Close()
+	for _, doc := range getMockDocuments(10, dim) {
+		index.Add(doc)
+	}
+	// resuming from old offset fails
+	err := leader.Serve(ioutil.Discard, 1)
+	if !errors.Is(err, knn.ErrLogTruncated) {
+		t.Fatalf("unexpected error, expected: %v, got: %v", knn.ErrLogTruncated, err)
+	}
+	// offset ahead of the leader is rejected
+	if err := leader.Serve(ioutil.Discard, 100); err == nil {
+		t.Fatalf("expected error for offset ahead of the leader")
+	}
+	// follower starts over after resetting its offset
+	replica := newReplicaIndex(t, dim)
+	follower := knn.NewFollower(replica)
+	pr, pw := io.Pipe()
+	go func() {
+		leader.Serve(pw, follower.Seq())
+		pw.Close()
+	}()
+	go follower.Apply(pr)
+	waitSeq(t, follower, 10)
+	checkReplica(t, index, replica)
+	follower.ResetOffset()
+	if follower.Seq() != 0 {
+		t.Fatalf("unexpected offset after reset, got: %v", follower.Seq())
+	}
+}
@@ -0,0 +1,156 @@
+package test
+
+import (
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/riandyrn/go-knn"
+)
+
+func TestShardedQuery(t *testing.T) {
+	// prepare documents
+	n := 2000
+	dim := 20
+	documents := getMockDocuments(n, dim)
+	configs := knn.Configs{
+		VectorDimension: dim,
+		NumHashTable:    5,
+		NumHyperplane:   4,
+		SlotSize:        5,
+	}
+	// sharded index must returns the same result as single index
+	single := newIndex(t, configs)
+	sharded := newShardedIndex(t, 4, configs)
+	for _, document := range documents {
+		single.Add(document)
+		sharded.Add(document)
+	}
+	k := 10
+	for i := 0; i < 20; i++ {
+		queryVector := getRandomVector(dim)
+		expDocs, err := single.Query(queryVector, k)
+		if err != nil {
+			t.Fatalf("unexpected error, err: %v", err)
+		}
+		resultDocs, err := sharded.Query(queryVector, k)
+		if err != nil {
+			t.Fatalf("unexpected error, err: %v", err)
+		}
+		if len(resultDocs) != len(expDocs) {
+			t.Fatalf("unexpected number of result, expected: %v, got: %v", len(expDocs), len(resultDocs))
+		}
+		for j := range expDocs {
+			if resultDocs[j].Distance != expDocs[j].Distance {
+				t.Fatalf("unexpected distance at %v, expected: %v, got: %v", j, expDocs[j].Distance, resultDocs[j].Distance)
+			}
+		}
+	}
+	// check stats
+	numDocuments := 0
+	for _, stats := range sharded.ShardStats() {
+		if stats.NumQueries != 20 {
+			t.Fatalf("unexpected number of queries, expected: 20, got: %v", stats.NumQueries)
+		}
+		numDocuments += stats.NumDocuments
+	}
+	if numDocuments != n {
+		t.Fatalf("unexpected number of documents, expected: %v, got: %v", n, numDocuments)
+	}
+}
+
+func TestShardedConcurrentRWOps(t *testing.T) {
+	// prepare index
+	n := 5000
+	dim := 100
+	documents := getMockDocuments(n, dim)
+	index := newShardedIndex(t, 8, knn.Configs{
+		VectorDimension: dim,
+		NumHashTable:    10,
+		NumHyperplane:   10,
+		SlotSize:        20,
+	})
+	// execute multiple goroutine to call multiple ops concurrently
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			switch i % 3 {
+			case 0:
+				index.Query(getRandomVector(dim), 5)
+			case 1:
+				index.Add(documents[i])
+			case 2:
+				index.Delete(documents[i-1].GetID())
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestShardedQueryLargeK(t *testing.T) {
+	// prepare index, use single hyperplane with large slot
+	// so all documents land on the same bucket
+	dim := 5
+	index := newShardedIndex(t, 4, knn.Configs{
+		VectorDimension: dim,
+		NumHashTable:    1,
+		NumHyperplane:   1,
+		SlotSize:        1000000,
+	})
+	for _, document := range getMockDocuments(10, dim) {
+		index.Add(document)
+	}
+	// the result must not allocate buffer for `k` documents
+	resultDocs, err := index.Query(getRandomVector(dim), math.MaxInt64)
+	if err != nil {
+		t.Fatalf("unexpected error, err: %v", err)
+	}
+	if len(resultDocs) != 10 {
+		t.Fatalf("unexpected number of result, expected: 10, got: %v", len(resultDocs))
+	}
+}
+
+func TestShardedCapacity(t *testing.T) {
+	testCases := []struct {
+		Name      string
+		NumShards int
+		Capacity  int
+		ExpMaxLen int
+	}{
+		{
+			Name:      "Test Capacity Divided Across Shards",
+			NumShards: 4,
+			Capacity:  100,
+			ExpMaxLen: 100,
+		},
+		{
+			Name:      "Test Capacity Less Than Number of Shards",
+			NumShards: 4,
+			Capacity:  2,
+			ExpMaxLen: 4,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			dim := 5
+			index := newShardedIndex(t, testCase.NumShards, knn.Configs{
+				VectorDimension: dim,
+				NumHashTable:    1,
+				NumHyperplane:   1,
+				SlotSize:        1000000,
+				Capacity:        testCase.Capacity,
+			})
+			for _, document := range getMockDocuments(1000, dim) {
+				if err := index.Add(document); err != nil {
+					t.Fatalf("unexpected error, err: %v", err)
+				}
+			}
+			if index.Len() > testCase.ExpMaxLen {
+				t.Fatalf("unexpected length, expected at most: %v, got: %v", testCase.ExpMaxLen, index.Len())
+			}
+		})
+	}
+}
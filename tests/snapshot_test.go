@@ -0,0 +1,137 @@
+package test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/riandyrn/go-knn"
+)
+
+func TestSnapshotCommit(t *testing.T) {
+	// initialize knn index
+	index := newIndex(t, knn.Configs{
+		VectorDimension:   5,
+		NumHashTable:      3,
+		NumHyperplane:     2,
+		SlotSize:          5,
+		SnapshotIsolation: true,
+	})
+	defer index.Close()
+	doc := newMockDoc("doc_1", []float64{0, 0, 0, 0, 0})
+	index.Add(doc)
+	// the document must not be visible before commit
+	resultDocs, err := index.Query(doc.GetVector(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error, err: %v", err)
+	}
+	if len(resultDocs) != 0 {
+		t.Fatalf("uncommitted document is visible on query")
+	}
+	// the document must be visible after commit
+	index.Commit()
+	resultDocs, err = index.Query(doc.GetVector(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error, err: %v", err)
+	}
+	if len(resultDocs) != 1 {
+		t.Fatalf("committed document is not visible on query")
+	}
+	// the same goes for delete
+	index.Delete(doc.GetID())
+	if v, _ := index.Get(doc.GetID()); v == nil {
+		t.Fatalf("uncommitted delete is visible on get")
+	}
+	index.Commit()
+	if v, _ := index.Get(doc.GetID()); v != nil {
+		t.Fatalf("committed delete is not visible on get")
+	}
+}
+
+func TestSnapshotPublishInterval(t *testing.T) {
+	// initialize knn index
+	index := newIndex(t, knn.Configs{
+		VectorDimension:   5,
+		NumHashTable:      3,
+		NumHyperplane:     2,
+		SlotSize:          5,
+		SnapshotIsolation: true,
+		PublishInterval:   10 * time.Millisecond,
+	})
+	defer index.Close()
+	index.Add(newMockDoc("doc_1", []float64{0, 0, 0, 0, 0}))
+	// wait until the document is published
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if v, _ := index.Get("doc_1"); v != nil {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("document is not published automatically")
+}
+
+func TestSnapshotBatchAtomicity(t *testing.T) {
+	// initialize knn index
+	dim := 10
+	index := newIndex(t, knn.Configs{
+		VectorDimension:   dim,
+		NumHashTable:      3,
+		NumHyperplane:     3,
+		SlotSize:          5,
+		SnapshotIsolation: true,
+	})
+	defer index.Close()
+	// every batch consists of documents with the same vector, so
+	// they always land on the same buckets
+	numBatches := 50
+	batchSize := 20
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				for b := 0; b < numBatches; b++ {
+					resultDocs, err := index.Query(getBatchVector(b, dim), batchSize)
+					if err != nil {
+						t.Errorf("unexpected error, err: %v", err)
+						return
+					}
+					numFound := 0
+					for _, resultDoc := range resultDocs {
+						if strings.HasPrefix(resultDoc.Document.GetID(), fmt.Sprintf("doc_%v_", b)) {
+							numFound++
+						}
+					}
+					if numFound != 0 && numFound != batchSize {
+						t.Errorf("observed partially applied batch %v, got %v documents", b, numFound)
+						return
+					}
+				}
+			}
+		}()
+	}
+	for b := 0; b < numBatches; b++ {
+		for j := 0; j < batchSize; j++ {
+			index.Add(newMockDoc(fmt.Sprintf("doc_%v_%v", b, j), getBatchVector(b, dim)))
+		}
+		index.Commit()
+	}
+	close(done)
+	wg.Wait()
+}
+
+func getBatchVector(b, dim int) []float64 {
+	vector := make([]float64, dim)
+	vector[b%dim] = float64(100 * (b + 1))
+	return vector
+}
@@ -0,0 +1,140 @@
+package test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/riandyrn/go-knn"
+)
+
+func TestAddSparse(t *testing.T) {
+	testCases := []struct {
+		Name      string
+		InputDoc  knn.SparseDocument
+		ExpErrNil bool
+	}{
+		{
+			Name:      "Test Nil Document",
+			InputDoc:  nil,
+			ExpErrNil: false,
+		},
+		{
+			Name:      "Test Empty Vector",
+			InputDoc:  newMockSparseDoc("doc_1", nil, nil),
+			ExpErrNil: false,
+		},
+		{
+			Name:      "Test Mismatch Indices & Values",
+			InputDoc:  newMockSparseDoc("doc_1", []int{1, 2}, []float64{1}),
+			ExpErrNil: false,
+		},
+		{
+			Name:      "Test Index Out of Range",
+			InputDoc:  newMockSparseDoc("doc_1", []int{1, 1000000}, []float64{1, 2}),
+			ExpErrNil: false,
+		},
+		{
+			Name:      "Test Unordered Indices",
+			InputDoc:  newMockSparseDoc("doc_1", []int{5, 2}, []float64{1, 2}),
+			ExpErrNil: false,
+		},
+		{
+			Name:      "Test Normal Document",
+			InputDoc:  newMockSparseDoc("doc_1", []int{2, 999999}, []float64{1, 2}),
+			ExpErrNil: true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			index := newIndex(t, knn.Configs{
+				VectorDimension:  1000000,
+				NumHashTable:     2,
+				NumHyperplane:    3,
+				SlotSize:         5,
+				SparseProjection: true,
+			})
+			err := index.AddSparse(testCase.InputDoc)
+			if (err == nil) != testCase.ExpErrNil {
+				t.Fatalf("unexpected error for case: %+v, err: %v", testCase, err)
+			}
+		})
+	}
+}
+
+func TestQuerySparse(t *testing.T) {
+	// initialize knn index
+	index := newIndex(t, knn.Configs{
+		VectorDimension:  1000000,
+		NumHashTable:     5,
+		NumHyperplane:    2,
+		SlotSize:         5,
+		SparseProjection: true,
+	})
+	docs := []*mockSparseDoc{
+		newMockSparseDoc("doc_1", []int{10, 500000}, []float64{1, 1}),
+		newMockSparseDoc("doc_2", []int{10, 500000, 999999}, []float64{1, 1, 0.5}),
+		newMockSparseDoc("doc_3", []int{7, 20}, []float64{30, 40}),
+	}
+	for _, doc := range docs {
+		if err := index.AddSparse(doc); err != nil {
+			t.Fatalf("unexpected error, err: %v", err)
+		}
+	}
+	// execute query
+	indices, values := docs[0].GetSparseVector()
+	resultDocs, err := index.QuerySparse(indices, values, 2)
+	if err != nil {
+		t.Fatalf("unexpected error, err: %v", err)
+	}
+	if len(resultDocs) != 2 {
+		t.Fatalf("unexpected number of result, expected: 2, got: %v", len(resultDocs))
+	}
+	if resultDocs[0].SparseDocument.GetID() != "doc_1" || resultDocs[1].SparseDocument.GetID() != "doc_2" {
+		t.Fatalf("unexpected result: %+v", resultDocs)
+	}
+	if resultDocs[1].Distance != 0.5 {
+		t.Fatalf("unexpected distance, expected: 0.5, got: %v", resultDocs[1].Distance)
+	}
+}
+
+func TestMixedSparseDenseQuery(t *testing.T) {
+	// initialize knn index
+	index := newIndex(t, knn.Configs{
+		VectorDimension: 5,
+		NumHashTable:    3,
+		NumHyperplane:   2,
+		SlotSize:        5,
+	})
+	index.Add(newMockDoc("dense_1", []float64{0, 1, 0, 0, 0}))
+	index.Add(newMockDoc("dense_2", []float64{8, 6, 5, 4, 9}))
+	index.AddSparse(newMockSparseDoc("sparse_1", []int{1, 2}, []float64{1, 1}))
+	// query with dense vector must return sparse document
+	resultDocs, err := index.Query([]float64{0, 1, 1, 0, 0}, 2)
+	if err != nil {
+		t.Fatalf("unexpected error, err: %v", err)
+	}
+	if len(resultDocs) != 2 || resultDocs[0].SparseDocument == nil || resultDocs[0].Distance != 0 {
+		t.Fatalf("unexpected result: %+v", resultDocs)
+	}
+	if resultDocs[1].Document == nil || resultDocs[1].Document.GetID() != "dense_1" || resultDocs[1].Distance != 1 {
+		t.Fatalf("unexpected result: %+v", resultDocs)
+	}
+	// query with sparse vector must return dense document
+	resultDocs, err = index.QuerySparse([]int{1}, []float64{1}, 1)
+	if err != nil {
+		t.Fatalf("unexpected error, err: %v", err)
+	}
+	if len(resultDocs) != 1 || resultDocs[0].Document == nil || math.Abs(resultDocs[0].Distance) > 1e-9 {
+		t.Fatalf("unexpected result: %+v", resultDocs)
+	}
+	// get must distinguish sparse & dense document
+	doc, err := index.Get("sparse_1")
+	if !errors.Is(err, knn.ErrNotFound) || doc != nil {
+		t.Fatalf("sparse document must not be returned by Get, got: %v, err: %v", doc, err)
+	}
+	sparseDoc, err := index.GetSparse("sparse_1")
+	if err != nil || sparseDoc == nil {
+		t.Fatalf("sparse document must be returned by GetSparse, err: %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/riandyrn/go-knn"
+)
+
+func getClusteredDocuments(n, dim int, spread float64) []knn.Document {
+	center := getRandomVector(dim)
+	documents := make([]knn.Document, 0, n)
+	for i := 0; i < n; i++ {
+		vector := make([]float64, dim)
+		for j := range vector {
+			vector[j] = center[j] + rand.NormFloat64()*spread
+		}
+		documents = append(documents, newMockDoc(fmt.Sprintf("doc_%v", i), vector))
+	}
+	return documents
+}
+
+func TestOversizedBuckets(t *testing.T) {
+	// prepare index, all documents fall into the same bucket
+	n := 500
+	dim := 10
+	index := newIndex(t, knn.Configs{
+		VectorDimension: dim,
+		NumHashTable:    2,
+		NumHyperplane:   4,
+		SlotSize:        1000,
+	})
+	for _, document := range getClusteredDocuments(n, dim, 0.1) {
+		index.Add(document)
+	}
+	// check diagnostics
+	buckets := index.OversizedBuckets(n)
+	if len(buckets) != 2 {
+		t.Fatalf("unexpected number of oversized buckets, expected: %v, got: %v", 2, len(buckets))
+	}
+	for _, bucket := range buckets {
+		if bucket.Size != n || bucket.Depth != 0 {
+			t.Fatalf("unexpected bucket stats: %+v", bucket)
+		}
+	}
+	if len(index.OversizedBuckets(n+1)) != 0 {
+		t.Fatalf("unexpected oversized buckets")
+	}
+}
+
+func TestMaxBucketSize(t *testing.T) {
+	// prepare index
+	n := 2000
+	dim := 10
+	maxBucketSize := 100
+	index := newIndex(t, knn.Configs{
+		VectorDimension: dim,
+		NumHashTable:    4,
+		NumHyperplane:   4,
+		SlotSize:        1000,
+		MaxBucketSize:   maxBucketSize,
+	})
+	documents := getClusteredDocuments(n, dim, 0.1)
+	for _, document := range documents {
+		index.Add(document)
+	}
+	// check buckets are bounded
+	if buckets := index.OversizedBuckets(maxBucketSize + 1); len(buckets) > 0 {
+		t.Fatalf("unexpected oversized bucket: %+v", buckets[0])
+	}
+	stats := index.Stats()
+	for _, tableStats := range stats.Tables {
+		if tableStats.NumSplitBuckets == 0 {
+			t.Fatalf("unexpected table stats: %+v", tableStats)
+		}
+	}
+	// check documents could still be found
+	for _, document := range documents[:100] {
+		resultDocs, _ := index.Query(document.GetVector(), 1)
+		if len(resultDocs) != 1 || resultDocs[0].Document.GetID() != document.GetID() {
+			t.Fatalf("unable to find document %v", document.GetID())
+		}
+	}
+	// check deleted documents are removed from split buckets
+	for _, document := range documents {
+		index.Delete(document.GetID())
+	}
+	stats = index.Stats()
+	if stats.NumDocuments != 0 || stats.HashTableBytes != 0 {
+		t.Fatalf("unexpected stats after deleting all documents: %+v", stats)
+	}
+}
+
+func TestSplitRecall(t *testing.T) {
+	// prepare indexes with & without splitting the buckets
+	n := 2000
+	dim := 10
+	k := 10
+	configs := knn.Configs{
+		VectorDimension: dim,
+		NumHashTable:    4,
+		NumHyperplane:   4,
+		SlotSize:        1000,
+	}
+	unsplit := newIndex(t, configs)
+	configs.MaxBucketSize = 100
+	split := newIndex(t, configs)
+	documents := getClusteredDocuments(n+100, dim, 0.1)
+	for _, document := range documents[:n] {
+		unsplit.Add(document)
+		split.Add(document)
+	}
+	// the queries are not indexed, so their neighbours might
+	// fall into the sibling slots of split buckets
+	numFound := 0
+	for _, query := range documents[n:] {
+		expDocs, _ := unsplit.Query(query.GetVector(), k)
+		resultDocs, _ := split.Query(query.GetVector(), k)
+		ids := map[string]bool{}
+		for _, resultDoc := range resultDocs {
+			ids[resultDoc.Document.GetID()] = true
+		}
+		for _, expDoc := range expDocs {
+			if ids[expDoc.Document.GetID()] {
+				numFound++
+			}
+		}
+	}
+	overlap := float64(numFound) / float64(k*(len(documents)-n))
+	if overlap < 0.9 {
+		t.Fatalf("unexpected overlap with unsplit results, got: %v", overlap)
+	}
+}
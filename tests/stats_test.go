@@ -0,0 +1,127 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/riandyrn/go-knn"
+)
+
+func TestStats(t *testing.T) {
+	// prepare index
+	n := 1000
+	dim := 20
+	numHashTable := 4
+	index := newIndex(t, knn.Configs{
+		VectorDimension: dim,
+		NumHashTable:    numHashTable,
+		NumHyperplane:   4,
+		SlotSize:        5,
+	})
+	emptyStats := index.Stats()
+	if emptyStats.NumDocuments != 0 || emptyStats.TotalBytes != 0 {
+		t.Fatalf("unexpected stats of empty index: %+v", emptyStats)
+	}
+	for _, document := range getMockDocuments(n, dim) {
+		index.Add(document)
+	}
+	// check stats
+	stats := index.Stats()
+	if stats.NumDocuments != n {
+		t.Fatalf("unexpected number of documents, expected: %v, got: %v", n, stats.NumDocuments)
+	}
+	if len(stats.Tables) != numHashTable {
+		t.Fatalf("unexpected number of tables, expected: %v, got: %v", numHashTable, len(stats.Tables))
+	}
+	for _, tableStats := range stats.Tables {
+		if tableStats.NumBuckets == 0 || tableStats.LargestBucketSize == 0 {
+			t.Fatalf("unexpected table stats: %+v", tableStats)
+		}
+	}
+	if stats.VectorBytes < int64(n*dim*8) {
+		t.Fatalf("vector bytes is too small, got: %v", stats.VectorBytes)
+	}
+	if stats.TotalBytes != stats.HashTableBytes+stats.BucketBytes+stats.DocumentBytes+stats.VectorBytes+stats.SnapshotBytes {
+		t.Fatalf("total bytes is not the sum of all sizes: %+v", stats)
+	}
+}
+
+func TestMemoryLimit(t *testing.T) {
+	// prepare index
+	dim := 20
+	memoryLimit := int64(100000)
+	index := newIndex(t, knn.Configs{
+		VectorDimension: dim,
+		NumHashTable:    4,
+		NumHyperplane:   4,
+		SlotSize:        5,
+		MemoryLimit:     memoryLimit,
+	})
+	// add documents until the limit is reached
+	documents := getMockDocuments(10000, dim)
+	var err error
+	numAdded := 0
+	for _, document := range documents {
+		if err = index.Add(document); err != nil {
+			break
+		}
+		numAdded++
+	}
+	if err != knn.ErrMemoryLimit {
+		t.Fatalf("unexpected error, expected: %v, got: %v", knn.ErrMemoryLimit, err)
+	}
+	if numAdded == 0 {
+		t.Fatalf("no document is added before limit is reached")
+	}
+	if stats := index.Stats(); stats.TotalBytes > memoryLimit {
+		t.Fatalf("memory usage exceeds the limit, got: %v", stats.TotalBytes)
+	}
+	// deleting documents must free some memory
+	for _, document := range documents[:5] {
+		index.Delete(document.GetID())
+	}
+	if err := index.Add(documents[numAdded]); err != nil {
+		t.Fatalf("unexpected error after delete, err: %v", err)
+	}
+}
+
+func TestSnapshotMemory(t *testing.T) {
+	dim := 20
+	index := newIndex(t, knn.Configs{
+		VectorDimension:   dim,
+		NumHashTable:      4,
+		NumHyperplane:     4,
+		SlotSize:          5,
+		SnapshotIsolation: true,
+	})
+	documents := getMockDocuments(100, dim)
+	for _, document := range documents {
+		index.Add(document)
+	}
+	// the snapshot is only copied on commit
+	if stats := index.Stats(); stats.SnapshotBytes != 0 {
+		t.Fatalf("unexpected snapshot size before commit: %v", stats.SnapshotBytes)
+	}
+	index.Commit()
+	stats := index.Stats()
+	if stats.SnapshotBytes <= 0 || stats.SnapshotBytes >= stats.TotalBytes-stats.SnapshotBytes {
+		t.Fatalf("unexpected snapshot size after commit: %v, total: %v", stats.SnapshotBytes, stats.TotalBytes)
+	}
+	// the snapshot counts toward the memory limit
+	limited := newIndex(t, knn.Configs{
+		VectorDimension:   dim,
+		NumHashTable:      4,
+		NumHyperplane:     4,
+		SlotSize:          5,
+		SnapshotIsolation: true,
+		MemoryLimit:       stats.TotalBytes - stats.SnapshotBytes/2,
+	})
+	for _, document := range documents {
+		if err := limited.Add(document); err != nil {
+			t.Fatalf("unexpected error before commit, err: %v", err)
+		}
+	}
+	limited.Commit()
+	if err := limited.Add(newMockDoc("doc_new", getRandomVector(dim))); err != knn.ErrMemoryLimit {
+		t.Fatalf("unexpected error after commit, expected: %v, got: %v", knn.ErrMemoryLimit, err)
+	}
+}
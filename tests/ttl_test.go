@@ -0,0 +1,89 @@
+package test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/riandyrn/go-knn"
+)
+
+func TestAddWithTTL(t *testing.T) {
+	// prepare index without sweeping
+	dim := 10
+	index := newIndex(t, knn.Configs{
+		VectorDimension: dim,
+		NumHashTable:    2,
+		NumHyperplane:   2,
+		SlotSize:        1000,
+	})
+	vector := getRandomVector(dim)
+	index.AddWithTTL(newMockDoc("doc_1", vector), 50*time.Millisecond)
+	index.Add(newMockExpiringDoc("doc_2", vector, time.Now().Add(50*time.Millisecond)))
+	index.Add(newMockExpiringDoc("doc_3", vector, time.Time{}))
+	index.Add(newMockDoc("doc_4", vector))
+	if err := index.AddWithTTL(newMockDoc("doc_5", vector), 0); err == nil {
+		t.Fatalf("expecting error on invalid ttl")
+	}
+	resultDocs, _ := index.Query(vector, 10)
+	if len(resultDocs) != 4 {
+		t.Fatalf("unexpected number of result, expected: %v, got: %v", 4, len(resultDocs))
+	}
+	// expired documents must not be returned before the sweep
+	time.Sleep(100 * time.Millisecond)
+	resultDocs, _ = index.Query(vector, 10)
+	if len(resultDocs) != 2 {
+		t.Fatalf("unexpected number of result, expected: %v, got: %v", 2, len(resultDocs))
+	}
+	for _, resultDoc := range resultDocs {
+		if id := resultDoc.Document.GetID(); id != "doc_3" && id != "doc_4" {
+			t.Fatalf("expired document %v found on result", id)
+		}
+	}
+	if _, err := index.Get("doc_1"); !errors.Is(err, knn.ErrNotFound) {
+		t.Fatalf("unexpected error on getting expired document, got: %v", err)
+	}
+	if index.Len() != 4 {
+		t.Fatalf("unexpected length before sweep, expected: %v, got: %v", 4, index.Len())
+	}
+	// unlike Len, IDs skips the expired documents
+	if ids, _ := index.IDs("", 10); !reflect.DeepEqual(ids, []string{"doc_3", "doc_4"}) {
+		t.Fatalf("unexpected ids before sweep, got: %v", ids)
+	}
+	// sweep expired documents
+	if count := index.DeleteExpired(); count != 2 {
+		t.Fatalf("unexpected number of swept documents, expected: %v, got: %v", 2, count)
+	}
+	if index.Len() != 2 {
+		t.Fatalf("unexpected length after sweep, expected: %v, got: %v", 2, index.Len())
+	}
+}
+
+func TestSweepInterval(t *testing.T) {
+	// prepare index
+	n := 100
+	dim := 10
+	index := newIndex(t, knn.Configs{
+		VectorDimension: dim,
+		NumHashTable:    2,
+		NumHyperplane:   2,
+		SlotSize:        5,
+		SweepInterval:   10 * time.Millisecond,
+	})
+	defer index.Close()
+	for _, document := range getMockDocuments(n, dim) {
+		index.AddWithTTL(document, 20*time.Millisecond)
+	}
+	// wait until the documents are swept
+	deadline := time.Now().Add(time.Second)
+	for index.Len() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expired documents are not swept, length: %v", index.Len())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if stats := index.Stats(); stats.TotalBytes != 0 {
+		t.Fatalf("unexpected stats after sweep: %+v", stats)
+	}
+}
@@ -0,0 +1,180 @@
+package test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/riandyrn/go-knn"
+	knnio "github.com/riandyrn/go-knn/io"
+)
+
+func TestVecsReadWrite(t *testing.T) {
+	testCases := []struct {
+		Name      string
+		Format    knnio.VecsFormat
+		Vectors   [][]float64
+		ExpErrNil bool
+	}{
+		{
+			Name:      "Test Fvecs",
+			Format:    knnio.Fvecs,
+			Vectors:   [][]float64{{0.5, -1.25, 3}, {2, 0, 1}},
+			ExpErrNil: true,
+		},
+		{
+			Name:      "Test Ivecs",
+			Format:    knnio.Ivecs,
+			Vectors:   [][]float64{{1, -2, 3, 4}, {100000, 0, 7, 8}},
+			ExpErrNil: true,
+		},
+		{
+			Name:      "Test Bvecs",
+			Format:    knnio.Bvecs,
+			Vectors:   [][]float64{{0, 128, 255}, {1, 2, 3}},
+			ExpErrNil: true,
+		},
+		{
+			Name:      "Test Bvecs Out Of Range",
+			Format:    knnio.Bvecs,
+			Vectors:   [][]float64{{0, 256, 1}},
+			ExpErrNil: false,
+		},
+		{
+			Name:      "Test Ivecs Not Integer",
+			Format:    knnio.Ivecs,
+			Vectors:   [][]float64{{0.5}},
+			ExpErrNil: false,
+		},
+		{
+			Name:      "Test Empty Vector",
+			Format:    knnio.Fvecs,
+			Vectors:   [][]float64{{}},
+			ExpErrNil: false,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := knnio.NewVecsWriter(&buf, testCase.Format)
+			var err error
+			for _, vector := range testCase.Vectors {
+				if err = w.Write(vector); err != nil {
+					break
+				}
+			}
+			if testCase.ExpErrNil != (err == nil) {
+				t.Fatalf("unexpected error, expected nil: %v, got: %v", testCase.ExpErrNil, err)
+			}
+			if err != nil {
+				return
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("unable to flush due: %v", err)
+			}
+			r := knnio.NewVecsReader(&buf, testCase.Format)
+			var vectors [][]float64
+			for {
+				vector, err := r.Read()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					t.Fatalf("unable to read vector due: %v", err)
+				}
+				vectors = append(vectors, vector)
+			}
+			if !reflect.DeepEqual(testCase.Vectors, vectors) {
+				t.Fatalf("unexpected vectors, expected: %v, got: %v", testCase.Vectors, vectors)
+			}
+		})
+	}
+}
+
+func TestVecsReadInvalid(t *testing.T) {
+	testCases := []struct {
+		Name  string
+		Input []byte
+	}{
+		{Name: "Test Truncated Dimension", Input: []byte{3, 0}},
+		{Name: "Test Truncated Values", Input: []byte{2, 0, 0, 0, 0, 0, 128, 63}},
+		{Name: "Test Negative Dimension", Input: []byte{255, 255, 255, 255}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			r := knnio.NewVecsReader(bytes.NewReader(testCase.Input), knnio.Fvecs)
+			_, err := r.Read()
+			if err == nil || errors.Is(err, io.EOF) {
+				t.Fatalf("expected error other than io.EOF, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestAddVecs(t *testing.T) {
+	dim := 4
+	vectors := make([][]float64, 25)
+	var buf bytes.Buffer
+	w := knnio.NewVecsWriter(&buf, knnio.Bvecs)
+	for i := range vectors {
+		vectors[i] = []float64{float64(i), 1, 2, 3}
+		if err := w.Write(vectors[i]); err != nil {
+			t.Fatalf("unable to write vector due: %v", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("unable to flush due: %v", err)
+	}
+
+	index := newIndex(t, knn.Configs{VectorDimension: dim, NumHashTable: 1, NumHyperplane: 1, SlotSize: 1000000})
+	n, err := knnio.AddVecs(index, knnio.NewVecsReader(&buf, knnio.Bvecs), 10)
+	if err != nil {
+		t.Fatalf("unable to add vectors due: %v", err)
+	}
+	if n != len(vectors) || index.Len() != len(vectors) {
+		t.Fatalf("unexpected number of documents, expected: %v, got: %v (index: %v)", len(vectors), n, index.Len())
+	}
+	// document id is the position of vector in the file
+	for i, vector := range vectors {
+		doc, err := index.Get(knnio.VecsID(i))
+		if err != nil {
+			t.Fatalf("unable to get document %v due: %v", i, err)
+		}
+		if !reflect.DeepEqual(vector, doc.GetVector()) {
+			t.Fatalf("unexpected vector of document %v, expected: %v, got: %v", i, vector, doc.GetVector())
+		}
+	}
+
+	// mismatch dimension is rejected by AddBatch
+	buf.Reset()
+	w = knnio.NewVecsWriter(&buf, knnio.Fvecs)
+	w.Write([]float64{1, 2})
+	w.Flush()
+	_, err = knnio.AddVecs(index, knnio.NewVecsReader(&buf, knnio.Fvecs), 10)
+	if !errors.Is(err, knn.ErrDimensionMismatch) {
+		t.Fatalf("unexpected error, expected: %v, got: %v", knn.ErrDimensionMismatch, err)
+	}
+}
+
+func TestReadGroundTruth(t *testing.T) {
+	rows := [][]int{{3, 1, 2}, {0, 4, 5}}
+	var buf bytes.Buffer
+	w := knnio.NewVecsWriter(&buf, knnio.Ivecs)
+	for _, row := range rows {
+		if err := w.WriteInts(row); err != nil {
+			t.Fatalf("unable to write row due: %v", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("unable to flush due: %v", err)
+	}
+	got, err := knnio.ReadGroundTruth(&buf)
+	if err != nil {
+		t.Fatalf("unable to read ground truth due: %v", err)
+	}
+	if !reflect.DeepEqual(rows, got) {
+		t.Fatalf("unexpected ground truth, expected: %v, got: %v", rows, got)
+	}
+}